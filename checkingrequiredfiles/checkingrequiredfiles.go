@@ -19,6 +19,7 @@ package checkingrequiredfiles
 
 import (
 	"bufio"
+	"errors"
 	"github.com/Appliscale/perun/cliparser"
 	"github.com/Appliscale/perun/configuration"
 	"github.com/Appliscale/perun/configurator"
@@ -45,6 +46,18 @@ func CheckingRequiredFiles(ctx *context.Context) {
 		myLogger.Error(mainError.Error())
 	}
 
+	//Checking if Mode needs AWS session. In offline mode nothing is downloaded.
+	if needsNoAWSSession() {
+		if !ctx.IsOffline() {
+			downloadError := downloadDefaultFiles()
+			if downloadError != nil {
+				myLogger.Error(downloadError.Error())
+			}
+		}
+		*ctx = initOffline(mainYAMLexists, homePath, ctx, &myLogger)
+		return
+	}
+
 	downloadError := downloadDefaultFiles()
 	if downloadError != nil {
 		myLogger.Error(downloadError.Error())
@@ -66,11 +79,6 @@ func CheckingRequiredFiles(ctx *context.Context) {
 		}
 		return
 	}
-	//Checking if Mode is "offline" or "online".
-	if isOffline() {
-		*ctx = initOffline(mainYAMLexists, homePath, ctx, &myLogger)
-		return
-	}
 
 	configAWSExists, configError := isAWSConfigPresent(&myLogger)
 	if configError != nil {
@@ -108,12 +116,18 @@ func CheckingRequiredFiles(ctx *context.Context) {
 	}
 }
 
-// Checking if Mode is "online" - needs config and credentials files or "offline" - needs only main.yaml.
-func isOffline() bool {
+// Checking if perun runs in offline mode or in Mode which doesn't use AWS session - needs only main.yaml,
+// otherwise needs config and credentials files too.
+func needsNoAWSSession() bool {
 	args, _ := cliparser.ParseCliArguments(os.Args)
-	offline := [3]string{cliparser.CreateParametersMode, cliparser.LintMode, cliparser.ConfigureMode}
-	for _, off := range offline {
-		if *args.Mode == off {
+	if args.Offline != nil && *args.Offline {
+		return true
+	}
+	withoutSession := [7]string{cliparser.CreateParametersMode, cliparser.LintMode, cliparser.ConfigureMode,
+		cliparser.SpecificationUpdateMode, cliparser.SpecificationListMode, cliparser.SpecificationPinMode,
+		cliparser.SpecificationMirrorMode}
+	for _, mode := range withoutSession {
+		if *args.Mode == mode {
 			return true
 		}
 	}
@@ -262,6 +276,7 @@ func downloadDefaultFiles() error {
 	urls["unblocked.json"] = "https://s3.amazonaws.com/perun-default-file/unblocked.json"
 	urls["style.yaml"] = "https://s3.amazonaws.com/perun-default-file/style.yaml"
 	urls["specification_inconsistency.yaml"] = "https://s3.amazonaws.com/perun-default-file/specification_inconsistency.yaml"
+	urls["restrictions.yaml"] = "https://s3.amazonaws.com/perun-default-file/restrictions.yaml"
 
 	for file, url := range urls {
 		homePath, _ := myuser.GetUserHomeDir()
@@ -278,21 +293,25 @@ func downloadDefaultFiles() error {
 
 		_, openError := os.Open(homePath + file) //checking if file exists
 		if openError != nil {
-			out, creatingFileError := os.Create(homePath + file)
-
-			if creatingFileError != nil {
-				return creatingFileError
-			}
-			defer out.Close()
-
 			resp, httpGetError := http.Get(url)
 			if httpGetError != nil {
 				return httpGetError
 			}
 			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return errors.New("Could not download " + url + ": " + resp.Status)
+			}
+
+			out, creatingFileError := os.Create(homePath + file)
+			if creatingFileError != nil {
+				return creatingFileError
+			}
+			defer out.Close()
 
 			_, copyError := io.Copy(out, resp.Body)
 			if copyError != nil {
+				out.Close()
+				os.Remove(homePath + file)
 				return copyError
 			}
 		}
@@ -156,6 +156,48 @@ func (mr *MockLoggerIntMockRecorder) AddResourceForValidation(resourceName inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddResourceForValidation", reflect.TypeOf((*MockLoggerInt)(nil).AddResourceForValidation), resourceName)
 }
 
+// GetResourceValidations mocks base method
+func (m *MockLoggerInt) GetResourceValidations() []*logger.ResourceValidation {
+	ret := m.ctrl.Call(m, "GetResourceValidations")
+	ret0, _ := ret[0].([]*logger.ResourceValidation)
+	return ret0
+}
+
+// GetResourceValidations indicates an expected call of GetResourceValidations
+func (mr *MockLoggerIntMockRecorder) GetResourceValidations() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResourceValidations", reflect.TypeOf((*MockLoggerInt)(nil).GetResourceValidations))
+}
+
+// AppendResourceValidations mocks base method
+func (m *MockLoggerInt) AppendResourceValidations(validations []*logger.ResourceValidation) {
+	m.ctrl.Call(m, "AppendResourceValidations", validations)
+}
+
+// AppendResourceValidations indicates an expected call of AppendResourceValidations
+func (mr *MockLoggerIntMockRecorder) AppendResourceValidations(validations interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendResourceValidations", reflect.TypeOf((*MockLoggerInt)(nil).AppendResourceValidations), validations)
+}
+
+// AddLintError mocks base method
+func (m *MockLoggerInt) AddLintError(position logger.Position, err string) {
+	m.ctrl.Call(m, "AddLintError", position, err)
+}
+
+// AddLintError indicates an expected call of AddLintError
+func (mr *MockLoggerIntMockRecorder) AddLintError(position, err interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLintError", reflect.TypeOf((*MockLoggerInt)(nil).AddLintError), position, err)
+}
+
+// AddLintWarning mocks base method
+func (m *MockLoggerInt) AddLintWarning(position logger.Position, warning string) {
+	m.ctrl.Call(m, "AddLintWarning", position, warning)
+}
+
+// AddLintWarning indicates an expected call of AddLintWarning
+func (mr *MockLoggerIntMockRecorder) AddLintWarning(position, warning interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLintWarning", reflect.TypeOf((*MockLoggerInt)(nil).AddLintWarning), position, warning)
+}
+
 // SetVerbosity mocks base method
 func (m *MockLoggerInt) SetVerbosity(verbosity string) {
 	m.ctrl.Call(m, "SetVerbosity", verbosity)
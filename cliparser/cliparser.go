@@ -41,6 +41,10 @@ var CreateChangeSetMode = "create-change-set"
 var DeleteChangeSetMode = "delete-change-set"
 var LintMode = "lint"
 var EstimateCostMode = "estimate-cost"
+var SpecificationUpdateMode = "spec-update"
+var SpecificationListMode = "spec-list"
+var SpecificationPinMode = "spec-pin"
+var SpecificationMirrorMode = "spec-mirror"
 
 var ChangeSetDefaultName string
 
@@ -50,6 +54,7 @@ const YAML = "yaml"
 type CliArguments struct {
 	Mode                    *string
 	TemplatePath            *string
+	TemplatePaths           *[]string
 	Parameters              *map[string]string
 	OutputFilePath          *string
 	ConfigurationPath       *string
@@ -74,6 +79,11 @@ type CliArguments struct {
 	Lint                    *bool
 	LinterConfiguration     *string
 	SkipValidation          *bool
+	OutputFormat            *string
+	Offline                 *bool
+	Jobs                    *int
+	SpecificationVersion    *string
+	MirrorDirectory         *string
 }
 
 // Get and validate CLI arguments. Returns error if validation fails.
@@ -94,15 +104,20 @@ func ParseCliArguments(args []string) (cliArguments CliArguments, err error) {
 		noValidate        = app.Flag("no-validate", "Disable validation before stack creation/update or creating Change Set.").Bool()
 
 		validate                  = app.Command(ValidateMode, "Template Validation")
-		validateTemplate          = validate.Arg("template", "A path to the template file.").Required().String()
+		validateTemplates         = validate.Arg("template", "Paths to template files, directories or glob patterns.").Required().Strings()
 		validateLint              = validate.Flag("lint", "Enable template linting").Bool()
 		validateLintConfiguration = validate.Flag("lint-configuration", "A path to the configuration file").String()
 		validateParams            = validate.Flag("parameter", "list of parameters").StringMap()
 		validateParametersFile    = validate.Flag("parameters-file", "filename with parameters").String()
+		validateOutputFormat      = validate.Flag("output-format", "Format of validation results: text | json | sarif | junit.").Default(logger.TextFormat).Enum(logger.OutputFormats...)
+		validateOffline           = validate.Flag("offline", "Validate template locally, without AWS credentials and network access. Only cached specification is used.").Bool()
+		validateJobs              = validate.Flag("jobs", "Number of templates validated concurrently (number of CPUs by default).").Short('j').Int()
+		validateSpecification     = validate.Flag("spec-version", "Version of resource specification used for validation (the pinned or the latest one by default).").String()
 
 		lint              = app.Command(LintMode, "Additional validation and template style checks")
 		lintTemplate      = lint.Arg("template", "A path to the template file.").Required().String()
 		lintConfiguration = lint.Flag("lint-configuration", "A path to the configuration file").String()
+		lintOutputFormat  = lint.Flag("output-format", "Format of lint results: text | json | sarif | junit.").Default(logger.TextFormat).Enum(logger.OutputFormats...)
 
 		configure = app.Command(ConfigureMode, "Create your own configuration mode")
 
@@ -164,6 +179,15 @@ func ParseCliArguments(args []string) (cliArguments CliArguments, err error) {
 		estimateCostTemplate       = estimateCost.Arg("template", "A path to the template file.").Required().String()
 		estimateCostParams         = estimateCost.Flag("parameter", "list of parameters").StringMap()
 		estimateCostParametersFile = estimateCost.Flag("parameters-file", "filename with parameters").String()
+
+		specification       = app.Command("spec", "Manage cached resource specifications.")
+		specificationUpdate = specification.Command("update", "Download the latest resource specification for the region.")
+		updateSpecification = specificationUpdate.Arg("version", "Version of resource specification to download instead of the latest one.").String()
+		specificationList   = specification.Command("list", "List resource specifications cached for the region.")
+		specificationPin    = specification.Command("pin", "Use the given version of resource specification for validation in the region.")
+		pinnedSpecification = specificationPin.Arg("version", "Version of resource specification, or latest to remove the pin.").Required().String()
+		specificationMirror = specification.Command("mirror", "Download resource specifications of all regions into a directory used as SpecificationMirror.")
+		mirrorDirectory     = specificationMirror.Arg("directory", "A path to the mirror directory.").Required().String()
 	)
 
 	app.HelpFlag.Short('h')
@@ -174,11 +198,16 @@ func ParseCliArguments(args []string) (cliArguments CliArguments, err error) {
 	// validate
 	case validate.FullCommand():
 		cliArguments.Mode = &ValidateMode
-		cliArguments.TemplatePath = validateTemplate
+		cliArguments.TemplatePaths = validateTemplates
+		cliArguments.TemplatePath = &(*validateTemplates)[0]
 		cliArguments.Lint = validateLint
 		cliArguments.LinterConfiguration = validateLintConfiguration
 		cliArguments.Parameters = validateParams
 		cliArguments.ParametersFile = validateParametersFile
+		cliArguments.OutputFormat = validateOutputFormat
+		cliArguments.Offline = validateOffline
+		cliArguments.Jobs = validateJobs
+		cliArguments.SpecificationVersion = validateSpecification
 
 		// configure
 	case configure.FullCommand():
@@ -188,6 +217,7 @@ func ParseCliArguments(args []string) (cliArguments CliArguments, err error) {
 		cliArguments.Mode = &LintMode
 		cliArguments.TemplatePath = lintTemplate
 		cliArguments.LinterConfiguration = lintConfiguration
+		cliArguments.OutputFormat = lintOutputFormat
 
 		// create Stack
 	case createStack.FullCommand():
@@ -272,6 +302,22 @@ func ParseCliArguments(args []string) (cliArguments CliArguments, err error) {
 		cliArguments.TemplatePath = estimateCostTemplate
 		cliArguments.Parameters = estimateCostParams
 		cliArguments.ParametersFile = estimateCostParametersFile
+
+		// manage cached specifications
+	case specificationUpdate.FullCommand():
+		cliArguments.Mode = &SpecificationUpdateMode
+		cliArguments.SpecificationVersion = updateSpecification
+
+	case specificationList.FullCommand():
+		cliArguments.Mode = &SpecificationListMode
+
+	case specificationPin.FullCommand():
+		cliArguments.Mode = &SpecificationPinMode
+		cliArguments.SpecificationVersion = pinnedSpecification
+
+	case specificationMirror.FullCommand():
+		cliArguments.Mode = &SpecificationMirrorMode
+		cliArguments.MirrorDirectory = mirrorDirectory
 	}
 
 	// OTHER FLAGS
@@ -297,6 +343,11 @@ func ParseCliArguments(args []string) (cliArguments CliArguments, err error) {
 		return
 	}
 
+	if cliArguments.Jobs != nil && *cliArguments.Jobs < 0 {
+		err = errors.New("You should specify value for number of jobs greater than zero")
+		return
+	}
+
 	if *cliArguments.Verbosity != "" && !logger.IsVerbosityValid(*cliArguments.Verbosity) {
 		err = errors.New("You specified invalid value for --verbosity flag")
 		return
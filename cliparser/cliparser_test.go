@@ -40,6 +40,61 @@ func TestValidArgs(t *testing.T) {
 	assert.Nil(t, parseCliArguments([]string{"cmd", "validate", "some_path"}))
 }
 
+func TestOutputFormat(t *testing.T) {
+	arguments, err := ParseCliArguments([]string{"cmd", "validate", "some_path", "--output-format=sarif"})
+	assert.Nil(t, err)
+	assert.Equal(t, "sarif", *arguments.OutputFormat)
+
+	arguments, err = ParseCliArguments([]string{"cmd", "lint", "some_path"})
+	assert.Nil(t, err)
+	assert.Equal(t, "text", *arguments.OutputFormat)
+}
+
+func TestManyTemplates(t *testing.T) {
+	arguments, err := ParseCliArguments([]string{"cmd", "validate", "first.yaml", "templates/", "nested/*.json", "--jobs=4"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"first.yaml", "templates/", "nested/*.json"}, *arguments.TemplatePaths)
+	assert.Equal(t, "first.yaml", *arguments.TemplatePath)
+	assert.Equal(t, 4, *arguments.Jobs)
+
+	assert.NotNil(t, parseCliArguments([]string{"cmd", "validate", "first.yaml", "--jobs=-1"}))
+}
+
+func TestOffline(t *testing.T) {
+	arguments, err := ParseCliArguments([]string{"cmd", "validate", "some_path", "--offline"})
+	assert.Nil(t, err)
+	assert.True(t, *arguments.Offline)
+
+	arguments, err = ParseCliArguments([]string{"cmd", "validate", "some_path"})
+	assert.Nil(t, err)
+	assert.False(t, *arguments.Offline)
+}
+
+func TestSpecificationCommands(t *testing.T) {
+	arguments, err := ParseCliArguments([]string{"cmd", "validate", "some_path", "--spec-version=18.6.0"})
+	assert.Nil(t, err)
+	assert.Equal(t, "18.6.0", *arguments.SpecificationVersion)
+
+	arguments, err = ParseCliArguments([]string{"cmd", "spec", "update"})
+	assert.Nil(t, err)
+	assert.Equal(t, SpecificationUpdateMode, *arguments.Mode)
+	assert.Equal(t, "", *arguments.SpecificationVersion)
+
+	arguments, err = ParseCliArguments([]string{"cmd", "spec", "list"})
+	assert.Nil(t, err)
+	assert.Equal(t, SpecificationListMode, *arguments.Mode)
+
+	arguments, err = ParseCliArguments([]string{"cmd", "spec", "pin", "18.6.0"})
+	assert.Nil(t, err)
+	assert.Equal(t, SpecificationPinMode, *arguments.Mode)
+	assert.Equal(t, "18.6.0", *arguments.SpecificationVersion)
+
+	arguments, err = ParseCliArguments([]string{"cmd", "spec", "mirror", "/mnt/specification"})
+	assert.Nil(t, err)
+	assert.Equal(t, SpecificationMirrorMode, *arguments.Mode)
+	assert.Equal(t, "/mnt/specification", *arguments.MirrorDirectory)
+}
+
 func parseCliArguments(args []string) error {
 	_, err := ParseCliArguments(args)
 	return err
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/Appliscale/perun/cliparser"
@@ -36,6 +37,10 @@ type Configuration struct {
 	DefaultRegion string
 	// Map of resource specification CloudFront URL per region.
 	SpecificationURL map[string]string
+	// Directory (or URL) of a mirror created with perun spec mirror. It's used instead of SpecificationURL.
+	SpecificationMirror string
+	// Time after which the cached latest resource specification is downloaded again (e.g. 168h). Never if it's empty.
+	SpecificationTTL string
 	// Decision regarding if we use MFA token or not.
 	DefaultDecisionForMFA bool
 	// Duration for MFA token.
@@ -44,16 +49,48 @@ type Configuration struct {
 	DefaultVerbosity string
 	// Directory for temporary files.
 	DefaultTemporaryFilesDirectory string
+	// CloudFormation service limits checked during validation.
+	TemplateLimits TemplateLimits
+	// Custom endpoint of CloudFormation API used for remote template validation (e.g. a local CloudFormation stand-in).
+	ValidationEndpoint string
 }
 
 // Return URL to specification file. If there is no specification file for selected region, return error.
 func (config Configuration) GetSpecificationFileURLForCurrentRegion() (string, error) {
+	return config.GetSpecificationFileURLForVersion("latest")
+}
+
+// Return URL to the given version of specification file ("latest" for the newest one) for selected region.
+func (config Configuration) GetSpecificationFileURLForVersion(version string) (string, error) {
+	url, err := config.GetSpecificationURLForCurrentRegion()
+	if err != nil {
+		return "", err
+	}
+	return url + "/" + version + "/gzip/CloudFormationResourceSpecification.json", nil
+}
+
+// Return base URL of specification files for selected region. Specification mirror takes precedence over
+// SpecificationURL and paths without scheme are turned into file:// URLs.
+func (config Configuration) GetSpecificationURLForCurrentRegion() (string, error) {
+	if config.SpecificationMirror != "" {
+		return toURL(strings.TrimSuffix(config.SpecificationMirror, "/") + "/" + config.DefaultRegion), nil
+	}
 	if url, ok := config.SpecificationURL[config.DefaultRegion]; ok {
-		return url + "/latest/gzip/CloudFormationResourceSpecification.json", nil
+		return toURL(url), nil
 	}
 	return "", errors.New("There is no specification file for region " + config.DefaultRegion)
 }
 
+func toURL(location string) string {
+	if strings.Contains(location, "://") {
+		return location
+	}
+	if absolutePath, err := filepath.Abs(location); err == nil {
+		location = absolutePath
+	}
+	return "file://" + filepath.ToSlash(location)
+}
+
 // Return perun configuration read from file.
 func GetConfiguration(cliArguments cliparser.CliArguments, logger logger.LoggerInt) (config Configuration, err error) {
 	mode := getMode(cliArguments)
@@ -46,6 +46,29 @@ func TestSpecificationFileURL(t *testing.T) {
 	setup([]string{"cmd", "validate", "some_path", "--config=test_resources/test_config.yaml"})
 	url, _ := configuration.GetSpecificationFileURLForCurrentRegion()
 	assert.Equal(t, "https://d1uauaxba7bl26.cloudfront.net/latest/gzip/CloudFormationResourceSpecification.json", url)
+
+	url, _ = configuration.GetSpecificationFileURLForVersion("18.6.0")
+	assert.Equal(t, "https://d1uauaxba7bl26.cloudfront.net/18.6.0/gzip/CloudFormationResourceSpecification.json", url)
+}
+
+func TestSpecificationMirror(t *testing.T) {
+	localConfiguration := Configuration{
+		DefaultRegion:    "eu-west-1",
+		SpecificationURL: map[string]string{"eu-west-1": "file:///mnt/specification/eu-west-1"},
+	}
+	url, err := localConfiguration.GetSpecificationFileURLForCurrentRegion()
+	assert.Nil(t, err)
+	assert.Equal(t, "file:///mnt/specification/eu-west-1/latest/gzip/CloudFormationResourceSpecification.json", url)
+
+	localConfiguration.SpecificationMirror = "/mnt/mirror/"
+	url, err = localConfiguration.GetSpecificationFileURLForVersion("18.6.0")
+	assert.Nil(t, err)
+	assert.Equal(t, "file:///mnt/mirror/eu-west-1/18.6.0/gzip/CloudFormationResourceSpecification.json", url)
+
+	localConfiguration.DefaultRegion = "someRegion"
+	url, err = localConfiguration.GetSpecificationURLForCurrentRegion()
+	assert.Nil(t, err)
+	assert.Equal(t, "file:///mnt/mirror/someRegion", url)
 }
 
 func TestNoSpecificationForRegion(t *testing.T) {
@@ -133,3 +156,15 @@ func TestCLIOverrideForVerbosity(t *testing.T) {
 	setup([]string{"cmd", "validate", "some_path", "--config=test_resources/test_config.yaml", "--verbosity=INFO"})
 	assert.Equal(t, "INFO", configuration.DefaultVerbosity)
 }
+
+func TestGettingTemplateLimitsFromConfigurationFile(t *testing.T) {
+	setup([]string{"cmd", "validate", "some_path", "--config=test_resources/test_config.yaml"})
+	limits := configuration.GetTemplateLimits()
+	assert.Equal(t, 1000, limits.MaxResources)
+	assert.Equal(t, DefaultTemplateLimits.MaxTemplateBodySize, limits.MaxTemplateBodySize)
+}
+
+func TestDefaultTemplateLimits(t *testing.T) {
+	setup([]string{"cmd", "validate", "some_path", "--sandbox"})
+	assert.Equal(t, DefaultTemplateLimits, configuration.GetTemplateLimits())
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+// TemplateLimits describes CloudFormation service limits checked during validation.
+// Limits which are not set in main.yaml take default values.
+type TemplateLimits struct {
+	// Maximum size of template body in bytes.
+	MaxTemplateBodySize int
+	// Maximum number of resources in a template.
+	MaxResources int
+	// Maximum number of outputs in a template.
+	MaxOutputs int
+	// Maximum number of parameters in a template.
+	MaxParameters int
+	// Maximum number of mappings in a template.
+	MaxMappings int
+	// Maximum number of attributes in a single mapping.
+	MaxMappingAttributes int
+	// Maximum length of a logical ID of resource, output, parameter, mapping or condition.
+	MaxLogicalIDLength int
+	// Maximum length of an output export name.
+	MaxExportNameLength int
+}
+
+// DefaultTemplateLimits are CloudFormation service limits for templates passed inline as TemplateBody, as listed in
+// https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/cloudformation-limits.html
+var DefaultTemplateLimits = TemplateLimits{
+	MaxTemplateBodySize:  51200,
+	MaxResources:         500,
+	MaxOutputs:           200,
+	MaxParameters:        200,
+	MaxMappings:          200,
+	MaxMappingAttributes: 64,
+	MaxLogicalIDLength:   255,
+	MaxExportNameLength:  255,
+}
+
+// GetTemplateLimits returns limits from the configuration, with default values for limits which are not set.
+func (config Configuration) GetTemplateLimits() TemplateLimits {
+	limits := config.TemplateLimits
+	setDefaultLimit(&limits.MaxTemplateBodySize, DefaultTemplateLimits.MaxTemplateBodySize)
+	setDefaultLimit(&limits.MaxResources, DefaultTemplateLimits.MaxResources)
+	setDefaultLimit(&limits.MaxOutputs, DefaultTemplateLimits.MaxOutputs)
+	setDefaultLimit(&limits.MaxParameters, DefaultTemplateLimits.MaxParameters)
+	setDefaultLimit(&limits.MaxMappings, DefaultTemplateLimits.MaxMappings)
+	setDefaultLimit(&limits.MaxMappingAttributes, DefaultTemplateLimits.MaxMappingAttributes)
+	setDefaultLimit(&limits.MaxLogicalIDLength, DefaultTemplateLimits.MaxLogicalIDLength)
+	setDefaultLimit(&limits.MaxExportNameLength, DefaultTemplateLimits.MaxExportNameLength)
+	return limits
+}
+
+func setDefaultLimit(limit *int, defaultValue int) {
+	if *limit <= 0 {
+		*limit = defaultValue
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/ghodss/yaml"
+)
+
+// RestrictionsConfiguration describes restrictions of property values per resource type and property path.
+type RestrictionsConfiguration struct {
+	Restrictions map[string]map[string]Restriction
+}
+
+// Restriction of a single property value. Only restrictions which are set are checked.
+type Restriction struct {
+	// Regular expression which the value has to match.
+	Pattern string
+	pattern *regexp.Regexp
+	// Allowed length of the value.
+	MinLength *int
+	MaxLength *int
+	// Values which the property can take.
+	AllowedValues []string
+	// Allowed range of a numeric value.
+	MinValue *float64
+	MaxValue *float64
+}
+
+// GetRestriction returns restriction for the property path in the resource type. Restrictions defined
+// for the specific resource type take precedence over restrictions defined for all resources ("*").
+func (config RestrictionsConfiguration) GetRestriction(resourceType string, propertyPath string) (Restriction, bool) {
+	if restriction, ok := config.Restrictions[resourceType][propertyPath]; ok {
+		return restriction, true
+	}
+	restriction, ok := config.Restrictions["*"][propertyPath]
+	return restriction, ok
+}
+
+// MatchesPattern checks if the value matches the pattern of the restriction. Value matches if there is no pattern.
+func (restriction Restriction) MatchesPattern(value string) bool {
+	return restriction.pattern == nil || restriction.pattern.MatchString(value)
+}
+
+// ReadRestrictionsConfiguration gets restrictions from restrictions.yaml in the user configuration directory,
+// where the default restrictions are downloaded. If could not read the file, no restrictions are used.
+func ReadRestrictionsConfiguration(logger logger.LoggerInt) (config RestrictionsConfiguration) {
+	if path, ok := getUserConfigFile(os.Stat, "restrictions.yaml"); ok {
+		rawConfig, err := ioutil.ReadFile(path)
+		if err != nil {
+			logger.Warning("Could not read restrictions configuration file")
+			return
+		}
+
+		config, err = ParseRestrictionsConfiguration(rawConfig, logger)
+		if err != nil {
+			logger.Warning("Restrictions configuration file format is invalid")
+		}
+		return
+	}
+
+	logger.Warning("Restrictions configuration file not found")
+	return
+}
+
+// ParseRestrictionsConfiguration parses restrictions and compiles their patterns. Restrictions with invalid
+// patterns are checked without the pattern.
+func ParseRestrictionsConfiguration(rawConfig []byte, logger logger.LoggerInt) (config RestrictionsConfiguration, err error) {
+	if err = yaml.Unmarshal(rawConfig, &config); err != nil {
+		return RestrictionsConfiguration{}, err
+	}
+
+	invalidPatterns := []string{}
+	for resourceType, restrictions := range config.Restrictions {
+		for propertyPath, restriction := range restrictions {
+			if restriction.Pattern == "" {
+				continue
+			}
+			pattern, compileError := regexp.Compile(restriction.Pattern)
+			if compileError != nil {
+				invalidPatterns = append(invalidPatterns, resourceType+" "+propertyPath)
+				restriction.Pattern = ""
+			}
+			restriction.pattern = pattern
+			restrictions[propertyPath] = restriction
+		}
+	}
+	if len(invalidPatterns) > 0 {
+		sort.Strings(invalidPatterns)
+		logger.Warning("Invalid patterns of restrictions will not be checked: " + strings.Join(invalidPatterns, ", "))
+	}
+	return
+}
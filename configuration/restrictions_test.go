@@ -0,0 +1,80 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDefaultRestrictions(t *testing.T) {
+	rawDefaults, err := ioutil.ReadFile("../defaults/restrictions.yaml")
+	assert.Nil(t, err)
+
+	sink := logger.CreateQuietLogger()
+	config, err := ParseRestrictionsConfiguration(rawDefaults, &sink)
+	assert.Nil(t, err)
+
+	restriction, ok := config.GetRestriction("AWS::S3::Bucket", "BucketName")
+	assert.True(t, ok)
+	assert.True(t, restriction.MatchesPattern("my-bucket"))
+	assert.False(t, restriction.MatchesPattern("My_Bucket"))
+}
+
+func TestParseRestrictionsWithInvalidPatterns(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	config, err := ParseRestrictionsConfiguration([]byte(`Restrictions:
+  AWS::S3::Bucket:
+    BucketName:
+      MaxLength: 63
+      Pattern: "^[a-z"
+  AWS::SNS::Topic:
+    TopicName:
+      Pattern: "(?<name>.*)"
+`), &sink)
+	assert.Nil(t, err)
+
+	restriction, ok := config.GetRestriction("AWS::S3::Bucket", "BucketName")
+	assert.True(t, ok)
+	assert.Equal(t, "", restriction.Pattern)
+	assert.Equal(t, 63, *restriction.MaxLength)
+	assert.True(t, restriction.MatchesPattern("anything"))
+}
+
+func TestGetRestriction(t *testing.T) {
+	maxLength := 10
+	config := RestrictionsConfiguration{
+		Restrictions: map[string]map[string]Restriction{
+			"*":               {"Tags.Key": {MaxLength: &maxLength}, "Name": {Pattern: "^[a-z]+$"}},
+			"AWS::S3::Bucket": {"Name": {Pattern: "^[0-9]+$"}},
+		},
+	}
+
+	restriction, ok := config.GetRestriction("AWS::S3::Bucket", "Name")
+	assert.True(t, ok)
+	assert.Equal(t, "^[0-9]+$", restriction.Pattern)
+
+	restriction, ok = config.GetRestriction("AWS::SNS::Topic", "Tags.Key")
+	assert.True(t, ok)
+	assert.Equal(t, 10, *restriction.MaxLength)
+
+	_, ok = config.GetRestriction("AWS::SNS::Topic", "TopicName")
+	assert.False(t, ok)
+}
@@ -23,20 +23,24 @@ import (
 	"github.com/Appliscale/perun/awsapi"
 	"github.com/Appliscale/perun/cliparser"
 	"github.com/Appliscale/perun/configuration"
+	"github.com/Appliscale/perun/helpers"
 	"github.com/Appliscale/perun/logger"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 )
 
 // Context contains perun's logger, configuration, information about inconsistency
-// between specification and documentation, and session.
+// between specification and documentation, restrictions of property values, session,
+// and downloader of snippets included with AWS::Include transform.
 type Context struct {
 	CliArguments        cliparser.CliArguments
 	Logger              logger.LoggerInt
 	Config              configuration.Configuration
 	InconsistencyConfig configuration.InconsistencyConfiguration
+	RestrictionsConfig  configuration.RestrictionsConfiguration
 	CloudFormation      awsapi.CloudFormationAPI
 	CurrentSession      *session.Session
+	DownloadSnippet     helpers.SnippetDownloader
 }
 
 type cliArgumentsParser func(args []string) (cliparser.CliArguments, error)
@@ -61,6 +65,10 @@ func GetContext(cliArgParser cliArgumentsParser, confReader configurationReader,
 		myLogger.Yes = *cliArguments.Yes
 	}
 
+	if cliArguments.OutputFormat != nil {
+		myLogger.OutputFormat = *cliArguments.OutputFormat
+	}
+
 	config, err := confReader(cliArguments, &myLogger)
 	if err != nil {
 		myLogger.Error(err.Error())
@@ -70,16 +78,23 @@ func GetContext(cliArgParser cliArgumentsParser, confReader configurationReader,
 	myLogger.SetVerbosity(config.DefaultVerbosity)
 
 	iconsistenciesConfig := inconsistReader(&myLogger)
+	restrictionsConfig := configuration.ReadRestrictionsConfiguration(&myLogger)
 
 	context = Context{
 		CliArguments:        cliArguments,
 		Logger:              &myLogger,
 		Config:              config,
 		InconsistencyConfig: iconsistenciesConfig,
+		RestrictionsConfig:  restrictionsConfig,
 	}
 	return
 }
 
+// IsOffline checks if perun works without AWS credentials and network access.
+func (context *Context) IsOffline() bool {
+	return context.CliArguments.Offline != nil && *context.CliArguments.Offline
+}
+
 // InitializeAwsAPI creates session.
 func (context *Context) InitializeAwsAPI() {
 	context.CurrentSession = InitializeSession(context)
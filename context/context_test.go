@@ -103,3 +103,12 @@ func TestCheckErroneousDependencyReturn(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestIsOffline(t *testing.T) {
+	offline := true
+	assert.True(t, (&Context{CliArguments: cliparser.CliArguments{Offline: &offline}}).IsOffline())
+
+	offline = false
+	assert.False(t, (&Context{CliArguments: cliparser.CliArguments{Offline: &offline}}).IsOffline())
+	assert.False(t, (&Context{}).IsOffline())
+}
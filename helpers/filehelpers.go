@@ -0,0 +1,127 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// Extensions of template files found in directories.
+var templateExtensions = []string{".json", ".yaml", ".yml"}
+
+// FindTemplateFiles expands paths to template files. A path can point at a file, a directory (searched recursively
+// for .json, .yaml and .yml files which are CloudFormation templates) or be a glob pattern, whose matches are filtered
+// like files in directories. Every file is returned once, in the order of the paths.
+func FindTemplateFiles(paths []string) (templateFiles []string, err error) {
+	found := make(map[string]bool)
+	add := func(file string) {
+		if !found[file] {
+			found[file] = true
+			templateFiles = append(templateFiles, file)
+		}
+	}
+
+	for _, path := range paths {
+		if info, statError := os.Stat(path); statError == nil && info.IsDir() {
+			directoryFiles, walkError := findTemplatesInDirectory(path)
+			if walkError != nil {
+				return nil, walkError
+			}
+			for _, file := range directoryFiles {
+				add(file)
+			}
+		} else if strings.ContainsAny(path, "*?[") {
+			matches, globError := filepath.Glob(path)
+			if globError != nil {
+				return nil, errors.New("Invalid pattern " + path + ": " + globError.Error())
+			}
+			if len(matches) == 0 {
+				return nil, errors.New("No template files match " + path)
+			}
+			sort.Strings(matches)
+			var matchedFiles []string
+			for _, match := range matches {
+				// Like in shell, hidden files and directories match only patterns starting with a dot.
+				if strings.HasPrefix(filepath.Base(match), ".") && !strings.HasPrefix(filepath.Base(path), ".") {
+					continue
+				}
+				files, walkError := walkTemplateFiles(match)
+				if walkError != nil {
+					return nil, walkError
+				}
+				matchedFiles = append(matchedFiles, files...)
+			}
+			if len(matchedFiles) == 0 {
+				return nil, errors.New("No template files match " + path)
+			}
+			for _, file := range matchedFiles {
+				add(file)
+			}
+		} else {
+			add(path)
+		}
+	}
+	return
+}
+
+func findTemplatesInDirectory(directory string) (templateFiles []string, err error) {
+	templateFiles, err = walkTemplateFiles(directory)
+	if err == nil && len(templateFiles) == 0 {
+		err = errors.New("No template files found in directory " + directory)
+	}
+	return
+}
+
+// walkTemplateFiles returns template files found in the root, which can be a directory or a single file.
+func walkTemplateFiles(root string) (templateFiles []string, err error) {
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && path != root && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && SliceContains(templateExtensions, strings.ToLower(filepath.Ext(path))) && isTemplateFile(path) {
+			templateFiles = append(templateFiles, path)
+		}
+		return nil
+	})
+	return
+}
+
+// isTemplateFile checks if the file contains a CloudFormation template - a map with Resources
+// or AWSTemplateFormatVersion, so other configuration files in the directory are skipped.
+func isTemplateFile(path string) bool {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var parsed map[string]interface{}
+	if err := yaml.Unmarshal(content, &parsed); err != nil {
+		return false
+	}
+	_, hasResources := parsed["Resources"]
+	_, hasVersion := parsed["AWSTemplateFormatVersion"]
+	return hasResources || hasVersion
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTemplateTree(t *testing.T) string {
+	root, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"a.yaml":             "Resources:\n  Bucket:\n    Type: AWS::S3::Bucket\n",
+		"b.json":             `{"AWSTemplateFormatVersion": "2010-09-09"}`,
+		"notes.txt":          "Resources: {}",
+		"nested/c.yml":       "Resources: {}",
+		"nested/config.yaml": "DefaultProfile: default",
+		"nested/list.json":   "[]",
+		".git/d.yaml":        "Resources: {}",
+	}
+	for file, content := range files {
+		path := filepath.Join(root, file)
+		os.MkdirAll(filepath.Dir(path), os.ModePerm)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestFindTemplateFiles(t *testing.T) {
+	root := createTemplateTree(t)
+	defer os.RemoveAll(root)
+
+	files, err := FindTemplateFiles([]string{filepath.Join(root, "b.json"), root, filepath.Join(root, "*.yaml")})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, "b.json"),
+		filepath.Join(root, "a.yaml"),
+		filepath.Join(root, "nested/c.yml"),
+	}, files)
+}
+
+func TestFindTemplateFilesMatchingGlob(t *testing.T) {
+	root := createTemplateTree(t)
+	defer os.RemoveAll(root)
+
+	files, err := FindTemplateFiles([]string{filepath.Join(root, "*")})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, "a.yaml"),
+		filepath.Join(root, "b.json"),
+		filepath.Join(root, "nested/c.yml"),
+	}, files)
+
+	_, err = FindTemplateFiles([]string{filepath.Join(root, "nested/*.json")})
+	assert.NotNil(t, err)
+}
+
+func TestFindTemplateFilesWithoutMatches(t *testing.T) {
+	root := createTemplateTree(t)
+	defer os.RemoveAll(root)
+
+	_, err := FindTemplateFiles([]string{filepath.Join(root, "*.template")})
+	assert.NotNil(t, err)
+
+	_, err = FindTemplateFiles([]string{filepath.Join(root, ".git")})
+	assert.Nil(t, err)
+
+	files, err := FindTemplateFiles([]string{"missing.yaml"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"missing.yaml"}, files)
+}
@@ -18,35 +18,156 @@
 package helpers
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"io/ioutil"
 	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/Appliscale/perun/intrinsicsolver"
 	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/transform"
 	"github.com/Appliscale/perun/validator/template"
 	"github.com/awslabs/goformation"
 	"github.com/awslabs/goformation/cloudformation"
 	"github.com/ghodss/yaml"
-	"regexp"
 )
 
-// GetParser chooses parser based on file extension.
-func GetParser(filename string) (func([]byte, template.Template, logger.LoggerInt) (cloudformation.Template, error), error) {
+// SnippetDownloader downloads snippets stored remotely (e.g. in S3), which are included with AWS::Include transform.
+// If it's nil, only snippets stored locally can be included.
+type SnippetDownloader func(location string) ([]byte, error)
+
+// GetParser chooses parser based on file extension. Snippets included with AWS::Include transform
+// from relative paths are resolved against the directory of the file, the ones stored remotely are downloaded with download.
+func GetParser(filename string, download SnippetDownloader) (func([]byte, template.Template, logger.LoggerInt) (cloudformation.Template, error), error) {
 	templateFileExtension := path.Ext(filename)
+	directory := filepath.Dir(filename)
 	if templateFileExtension == ".json" {
-		return ParseJSON, nil
+		return func(templateFile []byte, refTemplate template.Template, logger logger.LoggerInt) (cloudformation.Template, error) {
+			return parseJSON(templateFile, directory, download, refTemplate, logger)
+		}, nil
 	} else if templateFileExtension == ".yaml" || templateFileExtension == ".yml" {
-		return ParseYAML, nil
+		return func(templateFile []byte, refTemplate template.Template, logger logger.LoggerInt) (cloudformation.Template, error) {
+			return parseYAML(templateFile, directory, download, refTemplate, logger)
+		}, nil
 	} else {
 		return nil, errors.New("Invalid template file format.")
 	}
 }
 
+// ParseTemplate parses template file to perun's template. Unlike GetParser's parsers, it doesn't resolve intrinsic functions,
+// so references between the template elements can be validated.
+func ParseTemplate(filename string, templateFile []byte, download SnippetDownloader, logger logger.LoggerInt) (parsedTemplate template.Template, err error) {
+	templateFileExtension := path.Ext(filename)
+	directory := filepath.Dir(filename)
+	if templateFileExtension == ".json" {
+		expanded, expansionError := expandTransforms(templateFile, directory, download, true, logger)
+		if expansionError != nil {
+			return parsedTemplate, expansionError
+		}
+		err = json.Unmarshal(expanded, &parsedTemplate)
+	} else if templateFileExtension == ".yaml" || templateFileExtension == ".yml" {
+		preprocessed, preprocessingError := intrinsicsolver.FixFunctions(templateFile, logger, "multiline", "elongate", "correctlong")
+		if preprocessingError != nil {
+			return parsedTemplate, preprocessingError
+		}
+		expanded, expansionError := expandTransforms(preprocessed, directory, download, true, logger)
+		if expansionError != nil {
+			return parsedTemplate, expansionError
+		}
+		err = yaml.Unmarshal(expanded, &parsedTemplate)
+	} else {
+		err = errors.New("Invalid template file format.")
+	}
+	return
+}
+
+// IncludeSnippets includes snippets of AWS::Include transforms into the template, so it can be deployed without
+// uploading them to S3 first. If the template doesn't include any snippets, it's returned unchanged,
+// otherwise it's returned in JSON format.
+func IncludeSnippets(filename string, templateFile []byte, download SnippetDownloader, logger logger.LoggerInt) ([]byte, error) {
+	if !bytes.Contains(templateFile, []byte(transform.IncludeTransform)) {
+		return templateFile, nil
+	}
+	templateFileExtension := path.Ext(filename)
+	if templateFileExtension == ".yaml" || templateFileExtension == ".yml" {
+		preprocessed, err := intrinsicsolver.FixFunctions(templateFile, logger, "multiline", "elongate", "correctlong")
+		if err != nil {
+			return nil, err
+		}
+		templateFile = preprocessed
+	}
+	return expandTransforms(templateFile, filepath.Dir(filename), download, false, logger)
+}
+
+// expandTransforms expands transforms declared in the template, so it can be validated like any other template.
+// Snippets of AWS::Include transform are included first, then AWS::Serverless transform is expanded if withServerless
+// is set. The template has to be in JSON format or in YAML format with intrinsic functions in full form.
+// If any transform is expanded, the template is returned in JSON format.
+func expandTransforms(templateFile []byte, directory string, download SnippetDownloader, withServerless bool, logger logger.LoggerInt) ([]byte, error) {
+	hasInclude := bytes.Contains(templateFile, []byte(transform.IncludeTransform))
+	hasServerless := withServerless && bytes.Contains(templateFile, []byte(transform.ServerlessTransform))
+	if !hasInclude && !hasServerless {
+		return templateFile, nil
+	}
+	var elements map[string]interface{}
+	if err := yaml.Unmarshal(templateFile, &elements); err != nil {
+		return nil, err
+	}
+	if hasInclude {
+		if err := transform.ExpandIncludes(elements, snippetLoader(directory, download, logger)); err != nil {
+			return nil, err
+		}
+	}
+	if withServerless && transform.HasTransform(elements, transform.ServerlessTransform) {
+		if err := transform.ExpandServerless(elements, logger); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(elements)
+}
+
+// snippetLoader loads snippets stored remotely with download and snippets stored locally
+// from paths relative to the directory.
+func snippetLoader(directory string, download SnippetDownloader, logger logger.LoggerInt) transform.SnippetLoader {
+	return func(location string) (interface{}, error) {
+		var content []byte
+		var err error
+		if strings.Contains(location, "://") {
+			if download == nil {
+				return nil, errors.New("Snippets stored remotely can't be downloaded")
+			}
+			content, err = download(location)
+		} else {
+			if !filepath.IsAbs(location) {
+				location = filepath.Join(directory, location)
+			}
+			content, err = ioutil.ReadFile(location)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if path.Ext(location) != ".json" {
+			content, err = intrinsicsolver.FixFunctions(content, logger, "multiline", "elongate", "correctlong")
+			if err != nil {
+				return nil, err
+			}
+		}
+		var snippet interface{}
+		err = yaml.Unmarshal(content, &snippet)
+		return snippet, err
+	}
+}
+
 // ParseJSON parses JSON template file to cloudformation template.
 func ParseJSON(templateFile []byte, refTemplate template.Template, logger logger.LoggerInt) (template cloudformation.Template, err error) {
+	return parseJSON(templateFile, ".", nil, refTemplate, logger)
+}
+
+func parseJSON(templateFile []byte, directory string, download SnippetDownloader, refTemplate template.Template, logger logger.LoggerInt) (template cloudformation.Template, err error) {
 	err = json.Unmarshal(templateFile, &refTemplate)
 	if err != nil {
 		if syntaxError, isSyntaxError := err.(*json.SyntaxError); isSyntaxError {
@@ -61,6 +182,10 @@ func ParseJSON(templateFile []byte, refTemplate template.Template, logger logger
 		return template, err
 	}
 
+	templateFile, err = expandTransforms(templateFile, directory, download, true, logger)
+	if err != nil {
+		return template, err
+	}
 	tempJSON, err := goformation.ParseJSON(templateFile)
 	if err != nil {
 		logger.Error(err.Error())
@@ -73,25 +198,27 @@ func ParseJSON(templateFile []byte, refTemplate template.Template, logger logger
 
 // ParseYAML parses YAML template file to cloudformation template.
 func ParseYAML(templateFile []byte, refTemplate template.Template, logger logger.LoggerInt) (template cloudformation.Template, err error) {
+	return parseYAML(templateFile, ".", nil, refTemplate, logger)
+}
+
+func parseYAML(templateFile []byte, directory string, download SnippetDownloader, refTemplate template.Template, logger logger.LoggerInt) (template cloudformation.Template, err error) {
 	err = yaml.Unmarshal(templateFile, &refTemplate)
 	if err != nil {
 		return template, err
 	}
-	for resource := range refTemplate.Resources {
-		var validDeletionPolicy = regexp.MustCompile("(^$)|(Delete)$|(Retain)$|(Snapshot)$")
-		if !validDeletionPolicy.MatchString(refTemplate.Resources[resource].DeletionPolicy) {
-			err = errors.New("Deletion Policy in resource: " + resource + " has to be a string literal, cannot be parametrized")
-		}
-	}
 	preprocessed, preprocessingError := intrinsicsolver.FixFunctions(templateFile, logger, "multiline", "elongate", "correctlong")
 	if preprocessingError != nil {
 		logger.Error(preprocessingError.Error())
 	}
-	tempYAML, parseError := goformation.ParseYAML(preprocessed)
+	expanded, expansionError := expandTransforms(preprocessed, directory, download, true, logger)
+	if expansionError != nil {
+		return *cloudformation.NewTemplate(), expansionError
+	}
+	tempYAML, parseError := goformation.ParseYAML(expanded)
 	if parseError != nil {
 		return *cloudformation.NewTemplate(), parseError
 	}
-	findFnImportValue(preprocessed, tempYAML)
+	findFnImportValue(expanded, tempYAML)
 	returnTemplate := *tempYAML
 
 	return returnTemplate, err
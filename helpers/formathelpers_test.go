@@ -1,28 +1,31 @@
 package helpers
 
 import (
+	"encoding/json"
+	"github.com/Appliscale/perun/logger"
 	"github.com/awslabs/goformation/cloudformation"
 	"github.com/stretchr/testify/assert"
-	"reflect"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
 func TestGetParser(t *testing.T) {
-	parser, err := GetParser("myfile.json")
-	assert.Equal(t, reflect.ValueOf(ParseJSON), reflect.ValueOf(parser))
+	parser, err := GetParser("myfile.json", nil)
+	assert.NotNil(t, parser)
 	assert.Nil(t, err)
 
-	parser, err = GetParser("myfile.yaml")
-	assert.Equal(t, reflect.ValueOf(ParseYAML), reflect.ValueOf(parser))
+	parser, err = GetParser("myfile.yaml", nil)
+	assert.NotNil(t, parser)
 	assert.Nil(t, err)
 
-	parser, err = GetParser("myfile.yml")
-	assert.Equal(t, reflect.ValueOf(ParseYAML), reflect.ValueOf(parser))
+	parser, err = GetParser("myfile.yml", nil)
+	assert.NotNil(t, parser)
 	assert.Nil(t, err)
 
-	parser, err = GetParser("myfile.alamakota")
-	assert.NotEqual(t, reflect.ValueOf(ParseYAML), reflect.ValueOf(parser))
-	assert.NotEqual(t, reflect.ValueOf(ParseJSON), reflect.ValueOf(parser))
+	parser, err = GetParser("myfile.alamakota", nil)
+	assert.Nil(t, parser)
 	assert.NotNil(t, err)
 }
 
@@ -70,3 +73,104 @@ func TestAddToPathAndReplace(t *testing.T) {
 	assert.Nilf(t, err, "Error should be nil")
 
 }
+
+func TestParseTemplateWithServerlessTransform(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	template := []byte(`Transform: AWS::Serverless-2016-10-31
+Resources:
+  Function:
+    Type: AWS::Serverless::Function
+    Properties:
+      Handler: index.handler
+      Runtime: nodejs18.x
+      CodeUri: s3://code/function.zip
+      Environment:
+        Variables:
+          NAME: !Ref AWS::StackName
+`)
+	parsedTemplate, err := ParseTemplate("template.yaml", template, nil, &sink)
+	assert.Nil(t, err)
+	assert.Nil(t, parsedTemplate.Transform)
+	assert.Equal(t, "AWS::Lambda::Function", parsedTemplate.Resources["Function"].Type)
+	assert.Equal(t, "AWS::IAM::Role", parsedTemplate.Resources["FunctionRole"].Type)
+	assert.Equal(t, map[string]interface{}{"Variables": map[string]interface{}{"NAME": map[string]interface{}{"Ref": "AWS::StackName"}}},
+		parsedTemplate.Resources["Function"].Properties["Environment"])
+}
+
+func createSnippets(t *testing.T, files map[string]string) string {
+	directory, err := ioutil.TempDir("", "snippets")
+	assert.Nil(t, err)
+	for name, body := range files {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(directory, name), []byte(body), 0644))
+	}
+	return directory
+}
+
+func TestParseTemplateWithIncludeTransform(t *testing.T) {
+	directory := createSnippets(t, map[string]string{
+		"bucket.yaml": "Type: AWS::S3::Bucket\nProperties:\n  BucketName: !Ref AWS::StackName\n",
+		"queue.json":  `{"Resources": {"Queue": {"Type": "AWS::SQS::Queue"}}}`,
+	})
+	defer os.RemoveAll(directory)
+
+	sink := logger.CreateQuietLogger()
+	template := []byte(`Transform:
+  Name: AWS::Include
+  Parameters:
+    Location: queue.json
+Resources:
+  Bucket:
+    Fn::Transform:
+      Name: AWS::Include
+      Parameters:
+        Location: bucket.yaml
+`)
+	parsedTemplate, err := ParseTemplate(filepath.Join(directory, "template.yaml"), template, nil, &sink)
+	assert.Nil(t, err)
+	assert.Nil(t, parsedTemplate.Transform)
+	assert.Equal(t, "AWS::S3::Bucket", parsedTemplate.Resources["Bucket"].Type)
+	assert.Equal(t, map[string]interface{}{"Ref": "AWS::StackName"}, parsedTemplate.Resources["Bucket"].Properties["BucketName"])
+	assert.Equal(t, "AWS::SQS::Queue", parsedTemplate.Resources["Queue"].Type)
+
+	_, err = ParseTemplate(filepath.Join(directory, "other", "template.yaml"), template, nil, &sink)
+	assert.NotNil(t, err)
+}
+
+func TestIncludeSnippets(t *testing.T) {
+	directory := createSnippets(t, map[string]string{
+		"queue.yaml": "Type: AWS::SQS::Queue\n",
+	})
+	defer os.RemoveAll(directory)
+
+	sink := logger.CreateQuietLogger()
+	template := []byte(`{"Resources": {"Queue": {"Fn::Transform": {"Name": "AWS::Include", "Parameters": {"Location": "queue.yaml"}}}}}`)
+	included, err := IncludeSnippets(filepath.Join(directory, "template.json"), template, nil, &sink)
+	assert.Nil(t, err)
+	var elements map[string]interface{}
+	assert.Nil(t, json.Unmarshal(included, &elements))
+	assert.Equal(t, map[string]interface{}{"Queue": map[string]interface{}{"Type": "AWS::SQS::Queue"}}, elements["Resources"])
+
+	template = []byte(`{"Resources": {"Queue": {"Type": "AWS::SQS::Queue"}}}`)
+	included, err = IncludeSnippets(filepath.Join(directory, "template.json"), template, nil, &sink)
+	assert.Nil(t, err)
+	assert.Equal(t, template, included)
+}
+
+func TestIncludeSnippetsStoredRemotely(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	template := []byte(`{"Resources": {"Queue": {"Fn::Transform": {"Name": "AWS::Include", "Parameters": {"Location": "s3://bucket/queue.yaml"}}}}}`)
+	_, err := IncludeSnippets("template.json", template, nil, &sink)
+	assert.NotNil(t, err)
+
+	var downloaded string
+	download := func(location string) ([]byte, error) {
+		downloaded = location
+		return []byte("Type: AWS::SQS::Queue\n"), nil
+	}
+	included, err := IncludeSnippets("template.json", template, download, &sink)
+	assert.Nil(t, err)
+	assert.Equal(t, "s3://bucket/queue.yaml", downloaded)
+	var elements map[string]interface{}
+	assert.Nil(t, json.Unmarshal(included, &elements))
+	assert.Equal(t, map[string]interface{}{"Queue": map[string]interface{}{"Type": "AWS::SQS::Queue"}}, elements["Resources"])
+}
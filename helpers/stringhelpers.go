@@ -0,0 +1,73 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"sort"
+	"strings"
+)
+
+// EditDistance computes Levenshtein distance between two strings, ignoring letter case.
+func EditDistance(first string, second string) int {
+	a := []rune(strings.ToLower(first))
+	b := []rune(strings.ToLower(second))
+
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// ClosestMatch finds candidate most similar to the given name. It's used for "did you mean" suggestions.
+// Candidates which differ in more than a third of characters are not considered similar.
+func ClosestMatch(name string, candidates []string) (match string, found bool) {
+	sorted := append([]string{}, candidates...)
+	sort.Strings(sorted)
+
+	bestDistance := len(name)/3 + 1
+	for _, candidate := range sorted {
+		if distance := EditDistance(name, candidate); distance < bestDistance {
+			bestDistance = distance
+			match = candidate
+			found = true
+		}
+	}
+	return
+}
+
+func minInt(first int, others ...int) int {
+	min := first
+	for _, value := range others {
+		if value < min {
+			min = value
+		}
+	}
+	return min
+}
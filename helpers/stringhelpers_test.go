@@ -0,0 +1,46 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, EditDistance("BucketName", "BucketName"))
+	assert.Equal(t, 0, EditDistance("bucketname", "BucketName"))
+	assert.Equal(t, 2, EditDistance("BucketNmae", "BucketName"))
+	assert.Equal(t, 3, EditDistance("kitten", "sitting"))
+	assert.Equal(t, 4, EditDistance("", "Tags"))
+}
+
+func TestClosestMatch(t *testing.T) {
+	candidates := []string{"BucketName", "BucketEncryption", "Tags"}
+
+	match, found := ClosestMatch("BucketNmae", candidates)
+	assert.True(t, found)
+	assert.Equal(t, "BucketName", match)
+
+	match, found = ClosestMatch("Tag", candidates)
+	assert.True(t, found)
+	assert.Equal(t, "Tags", match)
+
+	_, found = ClosestMatch("VersioningConfiguration", candidates)
+	assert.False(t, found)
+}
@@ -19,12 +19,12 @@ package linter
 import (
 	"github.com/Appliscale/perun/context"
 	"github.com/Appliscale/perun/helpers"
+	"github.com/Appliscale/perun/logger"
 	"github.com/Appliscale/perun/validator/template"
 	"github.com/awslabs/goformation/cloudformation"
 	"io/ioutil"
 	"path"
 	"regexp"
-	"strconv"
 	"strings"
 )
 
@@ -70,22 +70,30 @@ func CheckStyle(ctx *context.Context) (err error) {
 	return
 }
 
+func templatePosition(ctx *context.Context) logger.Position {
+	return logger.Position{File: *ctx.CliArguments.TemplatePath}
+}
+
+func linePosition(ctx *context.Context, line int) logger.Position {
+	return logger.Position{File: *ctx.CliArguments.TemplatePath, Line: line}
+}
+
 func checkLineLengths(lines []string, lintConf LinterConfiguration, ctx *context.Context) {
 	for line := range lines {
 		if lintConf.Global.LineLength.Required && len(lines[line]) > int(lintConf.Global.LineLength.Value.(float64)) {
-			ctx.Logger.Warning("line " + strconv.Itoa(line+1) + ": maximum line lenght exceeded")
+			ctx.Logger.AddLintWarning(linePosition(ctx, line+1), "maximum line lenght exceeded")
 		}
 	}
 }
 
 func checkBlankLines(lintConf LinterConfiguration, rawTemplate string, ctx *context.Context) {
 	if !lintConf.Global.BlankLinesAllowed && regexp.MustCompile("\n\n").MatchString(rawTemplate) {
-		ctx.Logger.Warning("Blank lines are not allowed in current lint configuration")
+		ctx.Logger.AddLintWarning(templatePosition(ctx), "Blank lines are not allowed in current lint configuration")
 	}
 }
 func checkAWSCFSpecificStuff(ctx *context.Context, rawTemplate string, lintConf LinterConfiguration) {
 	var perunTemplate template.Template
-	parser, err := helpers.GetParser(*ctx.CliArguments.TemplatePath)
+	parser, err := helpers.GetParser(*ctx.CliArguments.TemplatePath, ctx.DownloadSnippet)
 	if err != nil {
 		ctx.Logger.Error(err.Error())
 		return
@@ -98,20 +106,20 @@ func checkAWSCFSpecificStuff(ctx *context.Context, rawTemplate string, lintConf
 	}
 
 	if lintConf.Global.RequiredFields.TemplateDescription && goFormationTemplate.Description == "" {
-		ctx.Logger.Warning("The template has no description")
+		ctx.Logger.AddLintWarning(templatePosition(ctx), "The template has no description")
 	}
 
 	if lintConf.Global.RequiredFields.ParametersDescription {
 		for parameterName, parameterValue := range goFormationTemplate.Parameters {
 			if parameterValue.(map[string]interface{})["Description"] == nil {
-				ctx.Logger.Warning("No description provided for parameter " + parameterName)
+				ctx.Logger.AddLintWarning(templatePosition(ctx), "No description provided for parameter "+parameterName)
 			}
 		}
 	}
 
 	for resourceName := range goFormationTemplate.Resources {
 		if !lintConf.CheckLogicalName(resourceName) {
-			ctx.Logger.Warning("Resource '" + resourceName + "' does not meet the given logical Name regex: " + lintConf.Global.NamingConventions.LogicalNames)
+			ctx.Logger.AddLintWarning(templatePosition(ctx), "Resource '"+resourceName+"' does not meet the given logical Name regex: "+lintConf.Global.NamingConventions.LogicalNames)
 		}
 	}
 }
@@ -121,12 +129,12 @@ func checkJsonSpaces(ctx *context.Context, lintConf LinterConfiguration, lines [
 	for line := range lines {
 		for sign := range lintConf.Json.Spaces.After {
 			if strings.Count(reg.ReplaceAllString(lines[line], "\"*\""), lintConf.Json.Spaces.After[sign]) != strings.Count(reg.ReplaceAllString(lines[line], "\"*\""), lintConf.Json.Spaces.After[sign]+" ") {
-				ctx.Logger.Warning("line " + strconv.Itoa(line+1) + ": no space after '" + string(lintConf.Json.Spaces.After[sign]) + "'")
+				ctx.Logger.AddLintWarning(linePosition(ctx, line+1), "no space after '"+string(lintConf.Json.Spaces.After[sign])+"'")
 			}
 		}
 		for sign := range lintConf.Json.Spaces.Before {
 			if strings.Count(reg.ReplaceAllString(lines[line], "\"*\""), lintConf.Json.Spaces.Before[sign]) != strings.Count(reg.ReplaceAllString(lines[line], "\"*\""), " "+lintConf.Json.Spaces.Before[sign]) {
-				ctx.Logger.Warning("line " + strconv.Itoa(line+1) + ": no space before '" + string(lintConf.Json.Spaces.Before[sign]) + "'")
+				ctx.Logger.AddLintWarning(linePosition(ctx, line+1), "no space before '"+string(lintConf.Json.Spaces.Before[sign])+"'")
 			}
 		}
 	}
@@ -137,24 +145,24 @@ func checkYamlLists(ctx *context.Context, lintConf LinterConfiguration, template
 	dashListRegex := regexp.MustCompile(".*- .*")
 	inlineListRegex := regexp.MustCompile(`.*: \[.*].*`)
 	if !lintConf.Yaml.AllowedLists.Dash && dashListRegex.MatchString(preprocessed) {
-		ctx.Logger.Warning("dash lists are not allowed in current lint configuration")
+		ctx.Logger.AddLintWarning(templatePosition(ctx), "dash lists are not allowed in current lint configuration")
 	}
 	if !lintConf.Yaml.AllowedLists.Inline && inlineListRegex.MatchString(preprocessed) {
-		ctx.Logger.Warning("inline lists are not allowed in current lint configuration")
+		ctx.Logger.AddLintWarning(templatePosition(ctx), "inline lists are not allowed in current lint configuration")
 	}
 }
 
 func checkYamlQuotes(ctx *context.Context, lintConf LinterConfiguration, lines []string) {
 	for line := range lines {
 		if !lintConf.Yaml.AllowedQuotes.Double && strings.Contains(lines[line], "\"") {
-			ctx.Logger.Warning("line " + strconv.Itoa(line+1) + ": double quotes not allowed")
+			ctx.Logger.AddLintWarning(linePosition(ctx, line+1), "double quotes not allowed")
 		}
 		if !lintConf.Yaml.AllowedQuotes.Single && strings.Contains(lines[line], "'") {
-			ctx.Logger.Warning("line " + strconv.Itoa(line+1) + ": single quotes not allowed")
+			ctx.Logger.AddLintWarning(linePosition(ctx, line+1), "single quotes not allowed")
 		}
 		noQuotesRegex := regexp.MustCompile(".*: [^\"']*")
 		if !lintConf.Yaml.AllowedQuotes.Noquotes && noQuotesRegex.MatchString(lines[line]) {
-			ctx.Logger.Warning("line " + strconv.Itoa(line+1) + ": quotes required")
+			ctx.Logger.AddLintWarning(linePosition(ctx, line+1), "quotes required")
 		}
 	}
 }
@@ -169,13 +177,13 @@ func checkYamlIndentation(ctx *context.Context, lintConf LinterConfiguration, li
 		curr_spaces := helpers.CountLeadingSpaces(lines[line])
 		if lintConf.Global.Indent.Required {
 			if curr_spaces%indent != 0 || (last_spaces < curr_spaces && last_spaces+indent != curr_spaces) {
-				ctx.Logger.Error("line " + strconv.Itoa(line+1) + ": indentation error")
+				ctx.Logger.AddLintError(linePosition(ctx, line+1), "indentation error")
 			}
 		}
 
 		if last_spaces < curr_spaces {
 			if wrongYAMLContinuationIndent(lintConf, lines, line, last_spaces, curr_spaces) {
-				ctx.Logger.Error("line " + strconv.Itoa(line+1) + ": continuation indent error")
+				ctx.Logger.AddLintError(linePosition(ctx, line+1), "continuation indent error")
 			}
 		}
 		last_spaces = curr_spaces
@@ -211,7 +219,7 @@ func checkJsonIndentation(ctx *context.Context, lintConf LinterConfiguration, li
 			}
 			curr_spaces := helpers.CountLeadingSpaces(lines[line])
 			if curr_spaces-last_spaces != indentation {
-				ctx.Logger.Error("line " + strconv.Itoa(line+1) + ": indentation error")
+				ctx.Logger.AddLintError(linePosition(ctx, line+1), "indentation error")
 			}
 			last_spaces = curr_spaces
 		}
@@ -6,7 +6,6 @@ import (
 	"github.com/Appliscale/perun/stack/stack_mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
-	"strconv"
 	"strings"
 	"testing"
 )
@@ -25,7 +24,7 @@ func TestCheckLineLengths(t *testing.T) {
 	ctx, mockCtrl, mockLogger, linterConf := setupTestEnv(t, "./test_resources/blanklines_testtemplate.yaml", "test_resources/test_style.yaml")
 	defer mockCtrl.Finish()
 
-	mockLogger.EXPECT().Warning("line " + strconv.Itoa(1) + ": maximum line lenght exceeded").Times(1)
+	mockLogger.EXPECT().AddLintWarning(linePosition(ctx, 1), "maximum line lenght exceeded").Times(1)
 
 	checkLineLengths([]string{"asdasdasdasdasd"}, linterConf, ctx)
 }
@@ -34,7 +33,7 @@ func TestCheckBlankLines(t *testing.T) {
 	ctx, mockCtrl, mockLogger, linterConf := setupTestEnv(t, "./test_resources/blanklines_testtemplate.yaml", "test_resources/test_style.yaml")
 	defer mockCtrl.Finish()
 
-	mockLogger.EXPECT().Warning("Blank lines are not allowed in current lint configuration").Times(1)
+	mockLogger.EXPECT().AddLintWarning(templatePosition(ctx), "Blank lines are not allowed in current lint configuration").Times(1)
 
 	checkBlankLines(linterConf, stack_mocks.ReadFile(t, "./test_resources/blanklines_testtemplate.yaml"), ctx)
 	checkBlankLines(linterConf, stack_mocks.ReadFile(t, "./test_resources/noblanklines_testtemplate.yaml"), ctx)
@@ -44,9 +43,9 @@ func TestCheckAWSSpecificStuff(t *testing.T) {
 	ctx, mockCtrl, mockLogger, linterConf := setupTestEnv(t, "./test_resources/nodescription_testtemplate.yaml", "test_resources/test_style.yaml")
 	defer mockCtrl.Finish()
 
-	mockLogger.EXPECT().Warning("The template has no description").Times(1)
-	mockLogger.EXPECT().Warning("No description provided for parameter TestParameter2")
-	mockLogger.EXPECT().Warning("Resource 'S3' does not meet the given logical Name regex: Test.+")
+	mockLogger.EXPECT().AddLintWarning(templatePosition(ctx), "The template has no description").Times(1)
+	mockLogger.EXPECT().AddLintWarning(templatePosition(ctx), "No description provided for parameter TestParameter2")
+	mockLogger.EXPECT().AddLintWarning(templatePosition(ctx), "Resource 'S3' does not meet the given logical Name regex: Test.+")
 
 	checkAWSCFSpecificStuff(ctx, stack_mocks.ReadFile(t, "./test_resources/nodescription_testtemplate.yaml"), linterConf)
 }
@@ -62,8 +61,8 @@ func TestCheckJSONSpaces(t *testing.T) {
 	ctx, mockCtrl, mockLogger, linterConf := setupTestEnv(t, "./test_resources/spacesjson_testtemplate.json", "test_resources/test_style.yaml")
 	defer mockCtrl.Finish()
 
-	mockLogger.EXPECT().Warning("line 3: no space after ':'")
-	mockLogger.EXPECT().Warning("line 2: no space before ':'")
+	mockLogger.EXPECT().AddLintWarning(linePosition(ctx, 3), "no space after ':'")
+	mockLogger.EXPECT().AddLintWarning(linePosition(ctx, 2), "no space before ':'")
 
 	checkJsonSpaces(ctx, linterConf, strings.Split(stack_mocks.ReadFile(t, "./test_resources/spacesjson_testtemplate.json"), "\n"))
 }
@@ -72,7 +71,7 @@ func TestCheckYamlDashLists(t *testing.T) {
 	ctx, mockCtrl, mockLogger, linterConf := setupTestEnv(t, "./test_resources/nodescription_testtemplate.yaml", "test_resources/test_style.yaml")
 	defer mockCtrl.Finish()
 
-	mockLogger.EXPECT().Warning("dash lists are not allowed in current lint configuration").Times(1)
+	mockLogger.EXPECT().AddLintWarning(templatePosition(ctx), "dash lists are not allowed in current lint configuration").Times(1)
 	checkYamlLists(ctx, linterConf, stack_mocks.ReadFile(t, "./test_resources/nodescription_testtemplate.yaml"))
 }
 
@@ -80,7 +79,7 @@ func TestCheckYamlInlineLists(t *testing.T) {
 	ctx, mockCtrl, mockLogger, linterConf := setupTestEnv(t, "./test_resources/nodescription_testtemplate.yaml", "test_resources/test_styleDash.yaml")
 	defer mockCtrl.Finish()
 
-	mockLogger.EXPECT().Warning("inline lists are not allowed in current lint configuration").Times(1)
+	mockLogger.EXPECT().AddLintWarning(templatePosition(ctx), "inline lists are not allowed in current lint configuration").Times(1)
 	checkYamlLists(ctx, linterConf, stack_mocks.ReadFile(t, "./test_resources/inlinelist_testtemplate.yaml"))
 }
 
@@ -88,13 +87,13 @@ func TestCheckYamlQuotesNoSingleDouble(t *testing.T) {
 	ctx, mockCtrl, mockLogger, linterConf := setupTestEnv(t, "./test_resources/nodescription_testtemplate.yaml", "test_resources/test_styleDash.yaml")
 	defer mockCtrl.Finish()
 
-	mockLogger.EXPECT().Warning("line 1: double quotes not allowed").Times(1)
-	mockLogger.EXPECT().Warning("line 2: double quotes not allowed").Times(1)
+	mockLogger.EXPECT().AddLintWarning(linePosition(ctx, 1), "double quotes not allowed").Times(1)
+	mockLogger.EXPECT().AddLintWarning(linePosition(ctx, 2), "double quotes not allowed").Times(1)
 
 	checkYamlQuotes(ctx, linterConf, []string{"ala: \"makota\"", "asd: \"qwe\""})
 
-	mockLogger.EXPECT().Warning("line 2: single quotes not allowed").Times(1)
-	mockLogger.EXPECT().Warning("line 3: single quotes not allowed").Times(1)
+	mockLogger.EXPECT().AddLintWarning(linePosition(ctx, 2), "single quotes not allowed").Times(1)
+	mockLogger.EXPECT().AddLintWarning(linePosition(ctx, 3), "single quotes not allowed").Times(1)
 
 	checkYamlQuotes(ctx, linterConf, []string{"asd: asd", "qwe: 'qwe'", "zxc: 'zxc'"})
 }
@@ -103,8 +102,8 @@ func TestCheckYamlQuotesNoQuotes(t *testing.T) {
 	ctx, mockCtrl, mockLogger, linterConf := setupTestEnv(t, "./test_resources/nodescription_testtemplate.yaml", "test_resources/test_style.yaml")
 	defer mockCtrl.Finish()
 
-	mockLogger.EXPECT().Warning("line 1: quotes required").Times(1)
-	mockLogger.EXPECT().Warning("line 2: quotes required").Times(1)
+	mockLogger.EXPECT().AddLintWarning(linePosition(ctx, 1), "quotes required").Times(1)
+	mockLogger.EXPECT().AddLintWarning(linePosition(ctx, 2), "quotes required").Times(1)
 
 	checkYamlQuotes(ctx, linterConf, []string{"asd: asd", "qwe: qwe"})
 }
@@ -115,8 +114,8 @@ func TestCheckYamlIndentation(t *testing.T) {
 
 	checkYamlIndentation(ctx, linterConf, strings.Split(stack_mocks.ReadFile(t, "./test_resources/blanklines_testtemplate.yaml"), "\n"))
 
-	mockLogger.EXPECT().Error("line 6: indentation error")
-	mockLogger.EXPECT().Error("line 8: indentation error")
+	mockLogger.EXPECT().AddLintError(linePosition(ctx, 6), "indentation error")
+	mockLogger.EXPECT().AddLintError(linePosition(ctx, 8), "indentation error")
 
 	checkYamlIndentation(ctx, linterConf, strings.Split(stack_mocks.ReadFile(t, "./test_resources/indenterror_testtemplate.yaml"), "\n"))
 
@@ -20,6 +20,9 @@ package logger
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strconv"
 	"strings"
 )
 
@@ -36,20 +39,57 @@ type LoggerInt interface {
 	HasValidationErrors() bool
 	HasValidationWarnings() bool
 	AddResourceForValidation(resourceName string) *ResourceValidation
+	GetResourceValidations() []*ResourceValidation
+	AppendResourceValidations(validations []*ResourceValidation)
+	AddLintError(position Position, err string)
+	AddLintWarning(position Position, warning string)
 	SetVerbosity(verbosity string)
 }
 type Logger struct {
-	Quiet              bool
-	Yes                bool
-	Verbosity          Verbosity
+	Quiet     bool
+	Yes       bool
+	Verbosity Verbosity
+	// Format of validation and lint results, one of OutputFormats. Text is used if it's empty.
+	OutputFormat string
+	// Writer of log messages and validation results. If it's nil, standard output is used
+	// (or standard error for log messages in machine-readable output formats).
+	Output             io.Writer
 	resourceValidation []*ResourceValidation
+	lintValidation     ResourceValidation
 }
 
 // ResourceValidation contains name of resource and errors.
 type ResourceValidation struct {
 	ResourceName string
+	// Path of the template which the element belongs to, when results of many templates are reported together.
+	TemplatePath string
 	Errors       []string
 	Warnings     []string
+	// Paths of elements inside the validated element which errors and warnings refer to (e.g. Properties.Name),
+	// in the same order as messages. Empty path refers to the validated element itself.
+	ErrorPaths   []string
+	WarningPaths []string
+	// Positions of errors and warnings in the template, in the same order as messages. Empty if not located.
+	ErrorPositions   []Position
+	WarningPositions []Position
+}
+
+// Position describes place in the template file which a validation message refers to.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// String returns position in file:line:column format. Line and column are skipped if they are unknown.
+func (position Position) String() string {
+	if position.Line == 0 {
+		return position.File
+	}
+	if position.Column == 0 {
+		return position.File + ":" + strconv.Itoa(position.Line)
+	}
+	return position.File + ":" + strconv.Itoa(position.Line) + ":" + strconv.Itoa(position.Column)
 }
 
 // Verbosity - type of logger.
@@ -95,7 +135,7 @@ func CreateQuietLogger() Logger {
 
 // Log always - no matter the verbosity level.
 func (logger *Logger) Always(message string) {
-	fmt.Println(message)
+	fmt.Fprintln(logger.resultWriter(), message)
 }
 
 // Log error.
@@ -123,14 +163,44 @@ func (logger *Logger) Trace(trace string) {
 	logger.log(TRACE, trace)
 }
 
-// Log validation error.
+// Log validation error about the whole validated element.
 func (resourceValidation *ResourceValidation) AddValidationError(error string) {
-	resourceValidation.Errors = append(resourceValidation.Errors, error)
+	resourceValidation.AddError("", error)
 }
 
-// Log validation error.
+// Log validation warning about the whole validated element.
 func (resourceValidation *ResourceValidation) AddValidationWarning(warning string) {
+	resourceValidation.AddWarning("", warning)
+}
+
+// AddError logs validation error about the element at the path inside the validated element, e.g. Properties.Name.
+func (resourceValidation *ResourceValidation) AddError(path string, error string) {
+	resourceValidation.Errors = append(resourceValidation.Errors, error)
+	resourceValidation.ErrorPaths = append(resourceValidation.ErrorPaths, path)
+}
+
+// AddWarning logs validation warning about the element at the path inside the validated element, e.g. Properties.Name.
+func (resourceValidation *ResourceValidation) AddWarning(path string, warning string) {
 	resourceValidation.Warnings = append(resourceValidation.Warnings, warning)
+	resourceValidation.WarningPaths = append(resourceValidation.WarningPaths, path)
+}
+
+// Locate sets positions of errors and warnings using the locate function, which gets paths of the elements
+// they refer to. Messages which have been already located keep their positions.
+func (resourceValidation *ResourceValidation) Locate(locate func(path string) Position) {
+	for index := len(resourceValidation.ErrorPositions); index < len(resourceValidation.Errors); index++ {
+		resourceValidation.ErrorPositions = append(resourceValidation.ErrorPositions, locate(pathAt(resourceValidation.ErrorPaths, index)))
+	}
+	for index := len(resourceValidation.WarningPositions); index < len(resourceValidation.Warnings); index++ {
+		resourceValidation.WarningPositions = append(resourceValidation.WarningPositions, locate(pathAt(resourceValidation.WarningPaths, index)))
+	}
+}
+
+func pathAt(paths []string, index int) string {
+	if index < len(paths) {
+		return paths[index]
+	}
+	return ""
 }
 
 // Get input from command line.
@@ -144,27 +214,62 @@ func (logger *Logger) GetInput(message string, v ...interface{}) error {
 }
 func (logger *Logger) log(verbosity Verbosity, message string) {
 	if !logger.Quiet && verbosity >= logger.Verbosity {
-		fmt.Println(verbosity.String() + ": " + message)
+		fmt.Fprintln(logger.messageWriter(), verbosity.String()+": "+message)
+	}
+}
+
+func (logger *Logger) messageWriter() io.Writer {
+	if logger.Output != nil {
+		return logger.Output
+	}
+	if logger.IsMachineReadable() {
+		// Standard output is reserved for the report.
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+func (logger *Logger) resultWriter() io.Writer {
+	if logger.Output != nil {
+		return logger.Output
 	}
+	return os.Stdout
+}
+
+// IsMachineReadable checks if results are printed as a report in a machine-readable output format.
+func (logger *Logger) IsMachineReadable() bool {
+	return logger.OutputFormat != "" && logger.OutputFormat != TextFormat
 }
 
-// Print validation error.
+// Print validation error. Results are printed as a report if machine-readable output format is chosen.
 func (logger *Logger) PrintValidationErrors() {
-	if !logger.Quiet {
+	if !logger.Quiet && logger.IsMachineReadable() {
+		if err := logger.writeReport(logger.resultWriter(), logger.OutputFormat); err != nil {
+			fmt.Fprintln(os.Stderr, ERROR.String()+": "+err.Error())
+		}
+	} else if !logger.Quiet {
 		for _, resourceValidation := range logger.resourceValidation {
 			if len(resourceValidation.Errors) != 0 || len(resourceValidation.Warnings) != 0 {
-				fmt.Println(resourceValidation.ResourceName)
-				for _, err := range resourceValidation.Errors {
-					fmt.Println("        ", err)
+				writer := logger.resultWriter()
+				fmt.Fprintln(writer, resourceValidation.ResourceName)
+				for index, err := range resourceValidation.Errors {
+					fmt.Fprintln(writer, "        ", withPosition(err, index, resourceValidation.ErrorPositions))
 				}
-				for _, warning := range resourceValidation.Warnings {
-					fmt.Println("        ", warning)
+				for index, warning := range resourceValidation.Warnings {
+					fmt.Fprintln(writer, "        ", withPosition(warning, index, resourceValidation.WarningPositions))
 				}
 			}
 		}
 	}
 }
 
+func withPosition(message string, index int, positions []Position) string {
+	if index < len(positions) && positions[index].File != "" {
+		return positions[index].String() + ": " + message
+	}
+	return message
+}
+
 // HasValidationErrors checks if resource has errors. It's used in validateResources().
 func (logger *Logger) HasValidationErrors() bool {
 	for _, resourceValidation := range logger.resourceValidation {
@@ -185,7 +290,13 @@ func (logger *Logger) HasValidationWarnings() bool {
 }
 
 // AddResourceForValidation : Adds resource for validation. It's used in validateResources().
+// If the resource has been already added, its validation is returned, so all messages about it are printed together.
 func (logger *Logger) AddResourceForValidation(resourceName string) *ResourceValidation {
+	for _, resourceValidation := range logger.resourceValidation {
+		if resourceValidation.ResourceName == resourceName {
+			return resourceValidation
+		}
+	}
 	resourceValidation := &ResourceValidation{
 		ResourceName: resourceName,
 	}
@@ -194,6 +305,50 @@ func (logger *Logger) AddResourceForValidation(resourceName string) *ResourceVal
 	return resourceValidation
 }
 
+// AppendResourceValidations adds validation results collected by another logger, e.g. for another template.
+// They are not merged with results of the same name, because they describe different elements.
+func (logger *Logger) AppendResourceValidations(validations []*ResourceValidation) {
+	logger.resourceValidation = append(logger.resourceValidation, validations...)
+}
+
+// GetResourceValidations returns validation results of all resources added for validation.
+func (logger *Logger) GetResourceValidations() []*ResourceValidation {
+	return logger.resourceValidation
+}
+
+// AddLintError adds error found by the linter. In text output format it is printed immediately.
+func (logger *Logger) AddLintError(position Position, err string) {
+	logger.addLintFinding(position, err, true)
+}
+
+// AddLintWarning adds warning found by the linter. In text output format it is printed immediately.
+func (logger *Logger) AddLintWarning(position Position, warning string) {
+	logger.addLintFinding(position, warning, false)
+}
+
+func (logger *Logger) addLintFinding(position Position, message string, isError bool) {
+	lintValidation := &logger.lintValidation
+	lintValidation.ResourceName = "Linter"
+	if isError {
+		lintValidation.AddValidationError(message)
+		lintValidation.ErrorPositions = append(lintValidation.ErrorPositions, position)
+	} else {
+		lintValidation.AddValidationWarning(message)
+		lintValidation.WarningPositions = append(lintValidation.WarningPositions, position)
+	}
+
+	if !logger.IsMachineReadable() {
+		if position.Line > 0 {
+			message = "line " + strconv.Itoa(position.Line) + ": " + message
+		}
+		if isError {
+			logger.Error(message)
+		} else {
+			logger.Warning(message)
+		}
+	}
+}
+
 // Set logger verbosity.
 func (logger *Logger) SetVerbosity(verbosity string) {
 	for index, element := range verboseModes {
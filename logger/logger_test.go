@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"bytes"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -30,6 +31,52 @@ func TestLogger_AddResourceForValidation(t *testing.T) {
 	assert.NotEmpty(t, logger.resourceValidation)
 }
 
+func TestLogger_AddResourceForValidationTwice(t *testing.T) {
+	logger := CreateQuietLogger()
+	first := logger.AddResourceForValidation("Name")
+	second := logger.AddResourceForValidation("Name")
+	assert.True(t, first == second)
+	assert.Len(t, logger.resourceValidation, 1)
+}
+
+func TestLogger_AppendResourceValidations(t *testing.T) {
+	logger := CreateQuietLogger()
+	logger.AddResourceForValidation("Name")
+	logger.AppendResourceValidations([]*ResourceValidation{{ResourceName: "Name"}})
+	assert.Len(t, logger.resourceValidation, 2)
+}
+
+func TestLogger_Output(t *testing.T) {
+	output := &bytes.Buffer{}
+	logger := Logger{Verbosity: INFO, Output: output}
+	logger.Info("Message")
+	logger.Debug("Hidden")
+	logger.Always("Result")
+	assert.Equal(t, "INFO: Message\nResult\n", output.String())
+}
+
+func TestResourceValidation_Locate(t *testing.T) {
+	resourceValidation := ResourceValidation{ResourceName: "Name"}
+	resourceValidation.AddError("Properties.Name", "Error")
+	resourceValidation.AddValidationWarning("Warning")
+	resourceValidation.Locate(func(path string) Position {
+		if path == "Properties.Name" {
+			return Position{File: "template.yaml", Line: 3, Column: 5}
+		}
+		return Position{File: "template.yaml"}
+	})
+	assert.Equal(t, "template.yaml:3:5", resourceValidation.ErrorPositions[0].String())
+	assert.Equal(t, "template.yaml", resourceValidation.WarningPositions[0].String())
+
+	resourceValidation.AddValidationError("Another error")
+	resourceValidation.Locate(func(path string) Position {
+		return Position{File: "nested.yaml", Line: 1, Column: 1}
+	})
+	assert.Equal(t, "template.yaml:3:5", resourceValidation.ErrorPositions[0].String())
+	assert.Equal(t, "nested.yaml:1:1", resourceValidation.ErrorPositions[1].String())
+	assert.Equal(t, []string{"Properties.Name", ""}, resourceValidation.ErrorPaths)
+}
+
 func TestLogger_SetVerbosity(t *testing.T) {
 	logger := CreateQuietLogger()
 	logger.SetVerbosity("error")
@@ -0,0 +1,308 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Appliscale/perun/utilities"
+)
+
+// Output formats of validation and lint results.
+const (
+	TextFormat  = "text"
+	JSONFormat  = "json"
+	SARIFFormat = "sarif"
+	JUnitFormat = "junit"
+)
+
+// OutputFormats lists all supported output formats.
+var OutputFormats = []string{TextFormat, JSONFormat, SARIFFormat, JUnitFormat}
+
+// Sources of reported findings.
+const (
+	validationSource = "validation"
+	lintSource       = "lint"
+)
+
+// finding is a single error or warning found by the validator or the linter.
+type finding struct {
+	Source   string `json:"source"`
+	Element  string `json:"element,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+func (logger *Logger) collectFindings() (findings []finding) {
+	collect := func(source string, resourceValidation *ResourceValidation) {
+		for index, err := range resourceValidation.Errors {
+			findings = append(findings, newFinding(source, resourceValidation.ResourceName, "error", err, index, resourceValidation.ErrorPositions))
+		}
+		for index, warning := range resourceValidation.Warnings {
+			findings = append(findings, newFinding(source, resourceValidation.ResourceName, "warning", warning, index, resourceValidation.WarningPositions))
+		}
+	}
+	for _, resourceValidation := range logger.resourceValidation {
+		collect(validationSource, resourceValidation)
+	}
+	collect(lintSource, &logger.lintValidation)
+	return
+}
+
+func newFinding(source string, element string, severity string, message string, index int, positions []Position) finding {
+	result := finding{Source: source, Element: element, Severity: severity, Message: message}
+	if source == lintSource {
+		result.Element = ""
+	}
+	if index < len(positions) {
+		result.File, result.Line, result.Column = positions[index].File, positions[index].Line, positions[index].Column
+	}
+	return result
+}
+
+type jsonReport struct {
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+	Findings []finding `json:"findings"`
+}
+
+func writeJSONReport(writer io.Writer, findings []finding) error {
+	report := jsonReport{Findings: findings}
+	if report.Findings == nil {
+		report.Findings = []finding{}
+	}
+	for _, result := range findings {
+		if result.Severity == "error" {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// Structures of the SARIF 2.1.0 log format.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+func writeSARIFReport(writer io.Writer, findings []finding) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "perun",
+			Version:        utilities.VersionNumber,
+			InformationURI: "https://github.com/Appliscale/perun",
+			Rules: []sarifRule{
+				{ID: validationSource, ShortDescription: sarifMessage{Text: "CloudFormation template validation"}},
+				{ID: lintSource, ShortDescription: sarifMessage{Text: "CloudFormation template style checks"}},
+			},
+		}},
+		Results: []sarifResult{},
+	}
+	for _, result := range findings {
+		sarif := sarifResult{
+			RuleID:  result.Source,
+			Level:   result.Severity,
+			Message: sarifMessage{Text: describeFinding(result)},
+		}
+		if result.File != "" {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: result.File}}}
+			if result.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: result.Line, StartColumn: result.Column}
+			}
+			sarif.Locations = []sarifLocation{location}
+		}
+		run.Results = append(run.Results, sarif)
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// Structures of the JUnit XML report format.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnitReport writes every validated element as a test case, which fails if the element has errors.
+// Warnings are added to the test case output. Test cases are classified by the template path, if it's known.
+func writeJUnitReport(writer io.Writer, validations []*ResourceValidation, lintValidation *ResourceValidation) error {
+	validationSuite := junitTestSuite{Name: validationSource}
+	for _, resourceValidation := range validations {
+		validationSuite.TestCases = append(validationSuite.TestCases, newJUnitTestCase(validationSource, resourceValidation))
+	}
+	lintSuite := junitTestSuite{Name: lintSource}
+	if len(lintValidation.Errors) > 0 || len(lintValidation.Warnings) > 0 {
+		lintSuite.TestCases = append(lintSuite.TestCases, newJUnitTestCase(lintSource, lintValidation))
+	}
+
+	report := junitTestSuites{Name: "perun"}
+	for _, suite := range []junitTestSuite{validationSuite, lintSuite} {
+		suite.Tests = len(suite.TestCases)
+		for _, testCase := range suite.TestCases {
+			if testCase.Failure != nil {
+				suite.Failures++
+			}
+		}
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+func newJUnitTestCase(source string, resourceValidation *ResourceValidation) junitTestCase {
+	testCase := junitTestCase{Name: resourceValidation.ResourceName, ClassName: "perun." + source}
+	if resourceValidation.TemplatePath != "" {
+		testCase.ClassName = resourceValidation.TemplatePath
+	}
+	var errors, warnings []string
+	for index, err := range resourceValidation.Errors {
+		errors = append(errors, withPosition(err, index, resourceValidation.ErrorPositions))
+	}
+	for index, warning := range resourceValidation.Warnings {
+		warnings = append(warnings, withPosition(warning, index, resourceValidation.WarningPositions))
+	}
+	if len(errors) > 0 {
+		testCase.Failure = &junitFailure{
+			Message: strconv.Itoa(len(errors)) + " error(s) found",
+			Type:    "error",
+			Text:    strings.Join(errors, "\n"),
+		}
+	}
+	testCase.SystemOut = strings.Join(warnings, "\n")
+	return testCase
+}
+
+func describeFinding(result finding) string {
+	if result.Element == "" {
+		return result.Message
+	}
+	return result.Element + ": " + result.Message
+}
+
+// writeReport writes all collected validation and lint results in the given machine-readable format.
+func (logger *Logger) writeReport(writer io.Writer, format string) error {
+	switch format {
+	case JSONFormat:
+		return writeJSONReport(writer, logger.collectFindings())
+	case SARIFFormat:
+		return writeSARIFReport(writer, logger.collectFindings())
+	case JUnitFormat:
+		return writeJUnitReport(writer, logger.resourceValidation, &logger.lintValidation)
+	}
+	return nil
+}
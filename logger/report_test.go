@@ -0,0 +1,108 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createLoggerWithResults(format string) Logger {
+	logger := CreateQuietLogger()
+	logger.OutputFormat = format
+
+	bucket := logger.AddResourceForValidation("Bucket")
+	bucket.AddValidationError("Property BucketName must be of type String")
+	bucket.AddValidationWarning("Property Tag is not supported")
+	bucket.Locate(func(path string) Position {
+		return Position{File: "template.yaml", Line: 4, Column: 3}
+	})
+	logger.AddResourceForValidation("Queue")
+	logger.AddLintWarning(Position{File: "template.yaml", Line: 7}, "quotes required")
+
+	return logger
+}
+
+func TestJSONReport(t *testing.T) {
+	logger := createLoggerWithResults(JSONFormat)
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, logger.writeReport(buffer, JSONFormat))
+
+	report := jsonReport{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &report))
+	assert.Equal(t, 1, report.Errors)
+	assert.Equal(t, 2, report.Warnings)
+	assert.Equal(t, finding{
+		Source:   "validation",
+		Element:  "Bucket",
+		Severity: "error",
+		Message:  "Property BucketName must be of type String",
+		File:     "template.yaml",
+		Line:     4,
+		Column:   3,
+	}, report.Findings[0])
+	assert.Equal(t, finding{Source: "lint", Severity: "warning", Message: "quotes required", File: "template.yaml", Line: 7}, report.Findings[2])
+}
+
+func TestSARIFReport(t *testing.T) {
+	logger := createLoggerWithResults(SARIFFormat)
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, logger.writeReport(buffer, SARIFFormat))
+
+	report := sarifLog{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &report))
+	assert.Equal(t, "2.1.0", report.Version)
+	assert.Len(t, report.Runs, 1)
+	results := report.Runs[0].Results
+	assert.Len(t, results, 3)
+	assert.Equal(t, "error", results[0].Level)
+	assert.Equal(t, "Bucket: Property BucketName must be of type String", results[0].Message.Text)
+	assert.Equal(t, "template.yaml", results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, &sarifRegion{StartLine: 4, StartColumn: 3}, results[0].Locations[0].PhysicalLocation.Region)
+	assert.Equal(t, "lint", results[2].RuleID)
+	assert.Equal(t, "warning", results[2].Level)
+}
+
+func TestJUnitReport(t *testing.T) {
+	logger := createLoggerWithResults(JUnitFormat)
+	logger.AppendResourceValidations([]*ResourceValidation{{ResourceName: "Queue", TemplatePath: "templates/queue.yaml"}})
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, logger.writeReport(buffer, JUnitFormat))
+
+	report := junitTestSuites{}
+	assert.Nil(t, xml.Unmarshal(buffer.Bytes(), &report))
+	assert.Equal(t, 4, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+
+	validation := report.Suites[0]
+	assert.Equal(t, "validation", validation.Name)
+	assert.Equal(t, "Bucket", validation.TestCases[0].Name)
+	assert.Equal(t, "template.yaml:4:3: Property BucketName must be of type String", validation.TestCases[0].Failure.Text)
+	assert.Equal(t, "template.yaml:4:3: Property Tag is not supported", validation.TestCases[0].SystemOut)
+	assert.Nil(t, validation.TestCases[1].Failure)
+	assert.Equal(t, "perun.validation", validation.TestCases[1].ClassName)
+	assert.Equal(t, "templates/queue.yaml", validation.TestCases[2].ClassName)
+
+	lint := report.Suites[1]
+	assert.Equal(t, 1, lint.Tests)
+	assert.Equal(t, 0, lint.Failures)
+	assert.Equal(t, "template.yaml:7: quotes required", lint.TestCases[0].SystemOut)
+}
@@ -24,9 +24,11 @@ import (
 	"github.com/Appliscale/perun/configurator"
 	"github.com/Appliscale/perun/context"
 	"github.com/Appliscale/perun/estimatecost"
+	"github.com/Appliscale/perun/helpers"
 	"github.com/Appliscale/perun/linter"
 	"github.com/Appliscale/perun/parameters"
 	"github.com/Appliscale/perun/progress"
+	"github.com/Appliscale/perun/specification"
 	"github.com/Appliscale/perun/stack"
 	"github.com/Appliscale/perun/utilities"
 	"github.com/Appliscale/perun/validator"
@@ -37,16 +39,19 @@ import (
 func main() {
 	ctx, err := context.GetContext(cliparser.ParseCliArguments, configuration.GetConfiguration, configuration.ReadInconsistencyConfiguration)
 	checkingrequiredfiles.CheckingRequiredFiles(&ctx)
+	ctx.DownloadSnippet = validator.NewSnippetDownloader(&ctx)
 
 	if ctx.CliArguments.Lint != nil && *ctx.CliArguments.Lint {
-		err = linter.CheckStyle(&ctx)
+		err = lintTemplates(&ctx)
 		if err != nil {
 			os.Exit(1)
 		}
 	}
 
 	if *ctx.CliArguments.Mode == cliparser.ValidateMode {
-		ctx.InitializeAwsAPI()
+		if !ctx.IsOffline() {
+			ctx.InitializeAwsAPI()
+		}
 		utilities.CheckFlagAndExit(validator.Validate(&ctx))
 	}
 
@@ -60,6 +65,7 @@ func main() {
 		if err != nil {
 			os.Exit(1)
 		}
+		ctx.Logger.PrintValidationErrors()
 		os.Exit(0)
 	}
 
@@ -140,6 +146,22 @@ func main() {
 		}
 	}
 
+	if *ctx.CliArguments.Mode == cliparser.SpecificationUpdateMode {
+		utilities.CheckErrorCodeAndExit(specification.UpdateSpecification(&ctx))
+	}
+
+	if *ctx.CliArguments.Mode == cliparser.SpecificationListMode {
+		utilities.CheckErrorCodeAndExit(specification.ListSpecifications(&ctx))
+	}
+
+	if *ctx.CliArguments.Mode == cliparser.SpecificationPinMode {
+		utilities.CheckErrorCodeAndExit(specification.PinSpecification(&ctx))
+	}
+
+	if *ctx.CliArguments.Mode == cliparser.SpecificationMirrorMode {
+		utilities.CheckErrorCodeAndExit(specification.MirrorSpecifications(&ctx, configurator.ResourceSpecificationURL, *ctx.CliArguments.MirrorDirectory))
+	}
+
 	if *ctx.CliArguments.Mode == cliparser.EstimateCostMode {
 		ctx.InitializeAwsAPI()
 		if *ctx.CliArguments.SkipValidation || validator.Validate(&ctx) {
@@ -149,3 +171,26 @@ func main() {
 		}
 	}
 }
+
+// lintTemplates checks style of every template given for validation.
+func lintTemplates(ctx *context.Context) error {
+	if ctx.CliArguments.TemplatePaths == nil {
+		return linter.CheckStyle(ctx)
+	}
+	templatePaths, err := helpers.FindTemplateFiles(*ctx.CliArguments.TemplatePaths)
+	if err != nil {
+		ctx.Logger.Error(err.Error())
+		return err
+	}
+	for index := range templatePaths {
+		templateContext := *ctx
+		templateContext.CliArguments.TemplatePath = &templatePaths[index]
+		if len(templatePaths) > 1 {
+			ctx.Logger.Info("Checking style of " + templatePaths[index])
+		}
+		if err = linter.CheckStyle(&templateContext); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -201,7 +201,7 @@ func parseTemplate(context *context.Context) (res cloudformation.Template, err e
 		return
 	}
 	myTemplate := template.Template{}
-	parser, err := helpers.GetParser(*context.CliArguments.TemplatePath)
+	parser, err := helpers.GetParser(*context.CliArguments.TemplatePath, context.DownloadSnippet)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,513 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package specification
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Appliscale/perun/context"
+)
+
+// LatestVersion refers to the newest version of specification.
+const LatestVersion = "latest"
+
+// Files in the cache directory which contain the latest downloaded and the pinned version of specification.
+const (
+	latestFile = "latest"
+	pinnedFile = "pinned"
+)
+
+// versionPattern matches version numbers of specification. Only such versions are used in paths of the cache.
+var versionPattern = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+// Number of versions of specification kept in the cache for a region. When a new version is downloaded,
+// the oldest ones are removed, except the latest and the pinned version.
+const maxCachedVersions = 5
+
+// cache stores versions of specification downloaded for a region side by side, so validation can be reproduced
+// with the same version later.
+type cache struct {
+	// Directory with versions of specification downloaded from the region's specification URL.
+	directory string
+	// Path to specification cached by older perun versions, which kept only one version.
+	legacyPath string
+	context    *context.Context
+}
+
+func newCache(context *context.Context) (cache, error) {
+	user, err := user.Current()
+	if err != nil {
+		return cache{}, err
+	}
+	return newCacheInDirectory(user.HomeDir+"/.config/perun/specification", context)
+}
+
+func newCacheInDirectory(specificationDir string, context *context.Context) (cache, error) {
+	url, err := context.Config.GetSpecificationURLForCurrentRegion()
+	if err != nil {
+		return cache{}, err
+	}
+	if index := strings.Index(url, "://"); index >= 0 {
+		url = url[index+len("://"):]
+	}
+	name := strings.NewReplacer("/", "_", ":", "_").Replace(strings.TrimSuffix(url, ".cloudfront.net"))
+	return cache{
+		directory:  filepath.Join(specificationDir, name),
+		legacyPath: filepath.Join(specificationDir, name+".json"),
+		context:    context,
+	}, nil
+}
+
+// getSpecificationPath returns path to the version of specification used for validation - the one given with
+// --spec-version, the pinned one or the latest one. Versions which are not cached are downloaded. The latest version
+// is downloaded again when SpecificationTTL from configuration passes.
+func (cache cache) getSpecificationPath() (string, error) {
+	version, err := cache.getRequestedVersion()
+	if err != nil {
+		return "", err
+	}
+	if version != LatestVersion {
+		if !cache.isCached(version) {
+			if _, err := cache.download(version); err != nil {
+				return "", err
+			}
+		}
+		return cache.path(version), nil
+	}
+
+	if err := cache.importLegacySpecification(); err != nil {
+		return "", err
+	}
+	latest, downloaded, err := cache.readVersionFile(latestFile)
+	if err != nil {
+		return "", err
+	}
+	if latest == "" || !cache.isCached(latest) {
+		if latest, err = cache.download(LatestVersion); err != nil {
+			return "", err
+		}
+	} else if !cache.context.IsOffline() && cache.isExpired(downloaded) {
+		refreshed, err := cache.download(LatestVersion)
+		if err != nil {
+			cache.context.Logger.Warning("Could not refresh specification, cached version " + latest + " is used: " + err.Error())
+		} else {
+			latest = refreshed
+		}
+	}
+	return cache.path(latest), nil
+}
+
+func (cache cache) getRequestedVersion() (string, error) {
+	if version := cache.context.CliArguments.SpecificationVersion; version != nil && *version != "" {
+		return *version, checkVersion(*version)
+	}
+	pinned, _, err := cache.readVersionFile(pinnedFile)
+	if err != nil || pinned == "" {
+		return LatestVersion, err
+	}
+	return pinned, checkVersion(pinned)
+}
+
+// checkVersion checks if the version is the latest one or a version number, so it can't point outside the cache.
+func checkVersion(version string) error {
+	if version != LatestVersion && !versionPattern.MatchString(version) {
+		return errors.New("Invalid specification version " + version + ", it has to be " + LatestVersion + " or a version number, e.g. 18.6.0")
+	}
+	return nil
+}
+
+func (cache cache) isExpired(downloaded time.Time) bool {
+	ttl := cache.context.Config.SpecificationTTL
+	if ttl == "" {
+		return false
+	}
+	duration, err := time.ParseDuration(ttl)
+	if err != nil {
+		cache.context.Logger.Warning("Invalid SpecificationTTL " + ttl + " in configuration, cached specification is not refreshed")
+		return false
+	}
+	return time.Since(downloaded) > duration
+}
+
+// download downloads the version of specification into the cache and returns the version number.
+// Specification is stored only if it's valid and has the requested version.
+func (cache cache) download(version string) (string, error) {
+	region := cache.context.Config.DefaultRegion
+	specificationFileURL, err := cache.context.Config.GetSpecificationFileURLForVersion(version)
+	if err != nil {
+		return "", err
+	}
+	if cache.context.IsOffline() && !isLocalURL(specificationFileURL) {
+		description := "Specification"
+		if version != LatestVersion {
+			description += " " + version
+		}
+		return "", errors.New(description + " for region " + region + " is not cached in " + cache.directory +
+			", run validation without --offline once to download it")
+	}
+
+	content, specification, err := fetchSpecification(specificationFileURL, version)
+	if err != nil {
+		return "", err
+	}
+	downloaded := specification.ResourceSpecificationVersion
+	if err := cache.store(downloaded, content); err != nil {
+		return "", err
+	}
+	if version == LatestVersion {
+		if err := cache.writeVersionFile(latestFile, downloaded); err != nil {
+			return "", err
+		}
+	}
+	cache.context.Logger.Info("Specification " + downloaded + " for region " + region + " downloaded")
+	if err := cache.removeOldVersions(downloaded); err != nil {
+		cache.context.Logger.Warning("Could not remove old versions of specification from " + cache.directory + ": " + err.Error())
+	}
+	return downloaded, nil
+}
+
+// removeOldVersions removes the oldest versions of specification when more than maxCachedVersions are cached.
+// The latest, the pinned and the just downloaded version are always kept.
+func (cache cache) removeOldVersions(downloaded string) error {
+	versions, err := cache.versions()
+	if err != nil {
+		return err
+	}
+	latest, _, err := cache.readVersionFile(latestFile)
+	if err != nil {
+		return err
+	}
+	pinned, _, err := cache.readVersionFile(pinnedFile)
+	if err != nil {
+		return err
+	}
+	excess := len(versions) - maxCachedVersions
+	for _, version := range versions {
+		if excess <= 0 {
+			break
+		}
+		if version == latest || version == pinned || version == downloaded {
+			continue
+		}
+		if err := cache.remove(version); err != nil {
+			return err
+		}
+		excess--
+	}
+	return nil
+}
+
+// remove removes the version of specification from the cache together with its compiled form.
+func (cache cache) remove(version string) error {
+	content, err := ioutil.ReadFile(cache.path(version))
+	if err != nil {
+		return err
+	}
+	if err := removeCompiledSpecification(content); err != nil {
+		return err
+	}
+	return os.Remove(cache.path(version))
+}
+
+// fetchSpecification downloads specification from the URL (or reads it from a file:// URL)
+// and verifies that it's valid and has the requested version.
+func fetchSpecification(specificationFileURL string, version string) ([]byte, Specification, error) {
+	var content []byte
+	var err error
+	if isLocalURL(specificationFileURL) {
+		content, err = ioutil.ReadFile(filepath.FromSlash(strings.TrimPrefix(specificationFileURL, "file://")))
+	} else {
+		content, err = downloadFile(specificationFileURL)
+	}
+	if err != nil {
+		return nil, Specification{}, err
+	}
+	specification, err := parseSpecificationFile(content)
+	if err == nil {
+		err = verifySpecification(specification, version)
+	}
+	if err != nil {
+		return nil, specification, errors.New("Specification from " + specificationFileURL + " is invalid: " + err.Error())
+	}
+	return content, specification, nil
+}
+
+func downloadFile(url string) ([]byte, error) {
+	response, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.New("Could not download specification from " + url + ": " + response.Status)
+	}
+	return ioutil.ReadAll(response.Body)
+}
+
+func isLocalURL(url string) bool {
+	return strings.HasPrefix(url, "file://")
+}
+
+func verifySpecification(specification Specification, version string) error {
+	if specification.ResourceSpecificationVersion == "" || len(specification.ResourceTypes) == 0 {
+		return errors.New("it doesn't contain resource types or its version")
+	}
+	if !versionPattern.MatchString(specification.ResourceSpecificationVersion) {
+		return errors.New("it has invalid version " + specification.ResourceSpecificationVersion)
+	}
+	if version != LatestVersion && specification.ResourceSpecificationVersion != version {
+		return errors.New("it has version " + specification.ResourceSpecificationVersion + " instead of " + version)
+	}
+	return nil
+}
+
+// store stores the version of specification in the cache. If the version was cached with different content,
+// the compiled form of the replaced content is removed.
+func (cache cache) store(version string, content []byte) error {
+	previous, readError := ioutil.ReadFile(cache.path(version))
+	if err := writeFile(cache.path(version), content); err != nil {
+		return err
+	}
+	if readError == nil && !bytes.Equal(previous, content) {
+		return removeCompiledSpecification(previous)
+	}
+	return nil
+}
+
+// writeFile writes content to a temporary file first and then moves it to the path,
+// so an interrupted download doesn't leave a broken specification.
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	temporaryFile, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = temporaryFile.Write(content)
+	if closeError := temporaryFile.Close(); err == nil {
+		err = closeError
+	}
+	if err == nil {
+		err = os.Rename(temporaryFile.Name(), path)
+	}
+	if err != nil {
+		os.Remove(temporaryFile.Name())
+	}
+	return err
+}
+
+// importLegacySpecification moves specification cached by older perun versions into the cache as the latest version.
+func (cache cache) importLegacySpecification() error {
+	info, err := os.Stat(cache.legacyPath)
+	if err != nil {
+		return nil
+	}
+	if latest, _, err := cache.readVersionFile(latestFile); err != nil || latest != "" {
+		return err
+	}
+	specification, err := GetSpecificationFromFile(cache.legacyPath)
+	if err != nil || !versionPattern.MatchString(specification.ResourceSpecificationVersion) {
+		return nil
+	}
+	version := specification.ResourceSpecificationVersion
+	if err := os.MkdirAll(cache.directory, os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.Rename(cache.legacyPath, cache.path(version)); err != nil {
+		return err
+	}
+	if err := cache.writeVersionFile(latestFile, version); err != nil {
+		return err
+	}
+	return os.Chtimes(filepath.Join(cache.directory, latestFile), info.ModTime(), info.ModTime())
+}
+
+func (cache cache) path(version string) string {
+	return filepath.Join(cache.directory, version+".json")
+}
+
+func (cache cache) isCached(version string) bool {
+	_, err := os.Stat(cache.path(version))
+	return err == nil
+}
+
+// versions returns cached versions of specification from the oldest to the newest.
+func (cache cache) versions() ([]string, error) {
+	files, err := ioutil.ReadDir(cache.directory)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for _, file := range files {
+		if !file.IsDir() && filepath.Ext(file.Name()) == ".json" {
+			versions = append(versions, strings.TrimSuffix(file.Name(), ".json"))
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) < 0
+	})
+	return versions, nil
+}
+
+// readVersionFile returns version stored in the cache file and time of its modification.
+// Empty version is returned if the file doesn't exist.
+func (cache cache) readVersionFile(name string) (version string, modified time.Time, err error) {
+	filePath := filepath.Join(cache.directory, name)
+	info, err := os.Stat(filePath)
+	if os.IsNotExist(err) {
+		return "", modified, nil
+	} else if err != nil {
+		return
+	}
+	content, err := ioutil.ReadFile(filePath)
+	return strings.TrimSpace(string(content)), info.ModTime(), err
+}
+
+func (cache cache) writeVersionFile(name string, version string) error {
+	if err := os.MkdirAll(cache.directory, os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(cache.directory, name), []byte(version+"\n"), 0644)
+}
+
+// compareVersions compares version numbers part by part, numerically if both parts are numbers.
+func compareVersions(first string, second string) int {
+	firstParts, secondParts := strings.Split(first, "."), strings.Split(second, ".")
+	for index := 0; index < len(firstParts) && index < len(secondParts); index++ {
+		firstNumber, firstError := strconv.Atoi(firstParts[index])
+		secondNumber, secondError := strconv.Atoi(secondParts[index])
+		if firstError == nil && secondError == nil {
+			if firstNumber != secondNumber {
+				return firstNumber - secondNumber
+			}
+		} else if firstParts[index] != secondParts[index] {
+			return strings.Compare(firstParts[index], secondParts[index])
+		}
+	}
+	return len(firstParts) - len(secondParts)
+}
+
+// UpdateSpecification downloads the latest specification for the region, or the version given in CLI arguments.
+func UpdateSpecification(context *context.Context) error {
+	cache, err := newCache(context)
+	if err == nil {
+		version := LatestVersion
+		if requested := context.CliArguments.SpecificationVersion; requested != nil && *requested != "" {
+			version = *requested
+		}
+		if err = checkVersion(version); err == nil {
+			_, err = cache.download(version)
+		}
+	}
+	if err != nil {
+		context.Logger.Error(err.Error())
+	}
+	return err
+}
+
+// ListSpecifications prints versions of specification cached for the region.
+func ListSpecifications(context *context.Context) error {
+	cache, err := newCache(context)
+	if err != nil {
+		context.Logger.Error(err.Error())
+		return err
+	}
+	if err = cache.importLegacySpecification(); err != nil {
+		context.Logger.Error(err.Error())
+		return err
+	}
+	versions, err := cache.versions()
+	if err != nil {
+		context.Logger.Error(err.Error())
+		return err
+	}
+	region := context.Config.DefaultRegion
+	if len(versions) == 0 {
+		context.Logger.Always("No specifications cached for region " + region)
+		return nil
+	}
+
+	latest, downloaded, _ := cache.readVersionFile(latestFile)
+	pinned, _, _ := cache.readVersionFile(pinnedFile)
+	context.Logger.Always("Specifications cached for region " + region + ":")
+	for _, version := range versions {
+		var labels []string
+		if version == latest {
+			labels = append(labels, "latest, downloaded "+downloaded.Format("2006-01-02 15:04"))
+		}
+		if version == pinned {
+			labels = append(labels, "pinned")
+		}
+		if len(labels) > 0 {
+			version += " (" + strings.Join(labels, ", ") + ")"
+		}
+		context.Logger.Always("    " + version)
+	}
+	return nil
+}
+
+// PinSpecification makes validation in the region use the version of specification given in CLI arguments.
+// The version is downloaded if it's not cached. Pinning the latest version removes the pin.
+func PinSpecification(context *context.Context) error {
+	cache, err := newCache(context)
+	if err == nil {
+		err = cache.pin(*context.CliArguments.SpecificationVersion)
+	}
+	if err != nil {
+		context.Logger.Error(err.Error())
+	}
+	return err
+}
+
+func (cache cache) pin(version string) error {
+	if err := checkVersion(version); err != nil {
+		return err
+	}
+	region := cache.context.Config.DefaultRegion
+	if version == LatestVersion {
+		err := os.Remove(filepath.Join(cache.directory, pinnedFile))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		cache.context.Logger.Info("The latest specification is used for region " + region)
+		return nil
+	}
+	if !cache.isCached(version) {
+		if _, err := cache.download(version); err != nil {
+			return err
+		}
+	}
+	if err := cache.writeVersionFile(pinnedFile, version); err != nil {
+		return err
+	}
+	cache.context.Logger.Info("Specification " + version + " is pinned for region " + region)
+	return nil
+}
@@ -0,0 +1,277 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package specification
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Appliscale/perun/cliparser"
+	"github.com/Appliscale/perun/configuration"
+	"github.com/Appliscale/perun/context"
+	"github.com/Appliscale/perun/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// specificationServer serves specifications of the given versions, the last one as the latest.
+func specificationServer(versions ...string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		version := strings.Split(strings.TrimPrefix(request.URL.Path, "/"), "/")[0]
+		if version == LatestVersion {
+			version = versions[len(versions)-1]
+		}
+		for _, served := range versions {
+			if served == version {
+				writer.Write([]byte(`{"ResourceSpecificationVersion": "` + version + `", "ResourceTypes": {"AWS::S3::Bucket": {}}}`))
+				return
+			}
+		}
+		http.NotFound(writer, request)
+	}))
+}
+
+func createCache(t *testing.T, url string, offline bool, specificationVersion string) (cache, func()) {
+	directory, err := ioutil.TempDir("", "specification")
+	assert.Nil(t, err)
+	quietLogger := logger.CreateQuietLogger()
+	ctx := &context.Context{
+		CliArguments: cliparser.CliArguments{Offline: &offline, SpecificationVersion: &specificationVersion},
+		Logger:       &quietLogger,
+		Config: configuration.Configuration{
+			DefaultRegion:    "test-region",
+			SpecificationURL: map[string]string{"test-region": url},
+			SpecificationTTL: "1h",
+		},
+	}
+	cache, err := newCacheInDirectory(directory, ctx)
+	assert.Nil(t, err)
+	defaultDirectory := compiledSpecificationDirectory
+	compiledSpecificationDirectory = func() (string, error) {
+		return filepath.Join(directory, "compiled"), nil
+	}
+	return cache, func() {
+		compiledSpecificationDirectory = defaultDirectory
+		os.RemoveAll(directory)
+	}
+}
+
+func TestDownloadLatestSpecification(t *testing.T) {
+	server := specificationServer("1.0.0", "2.0.0")
+	defer server.Close()
+	cache, cleanup := createCache(t, server.URL, false, "")
+	defer cleanup()
+
+	path, err := cache.getSpecificationPath()
+	assert.Nil(t, err)
+	assert.Equal(t, cache.path("2.0.0"), path)
+	latest, _, err := cache.readVersionFile(latestFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "2.0.0", latest)
+}
+
+func TestRefreshExpiredSpecification(t *testing.T) {
+	server := specificationServer("1.0.0", "2.0.0")
+	defer server.Close()
+	cache, cleanup := createCache(t, server.URL, false, "")
+	defer cleanup()
+
+	assert.Nil(t, cache.store("1.0.0", []byte("{}")))
+	assert.Nil(t, cache.writeVersionFile(latestFile, "1.0.0"))
+	path, err := cache.getSpecificationPath()
+	assert.Nil(t, err)
+	assert.Equal(t, cache.path("1.0.0"), path)
+
+	expired := time.Now().Add(-2 * time.Hour)
+	assert.Nil(t, os.Chtimes(filepath.Join(cache.directory, latestFile), expired, expired))
+	path, err = cache.getSpecificationPath()
+	assert.Nil(t, err)
+	assert.Equal(t, cache.path("2.0.0"), path)
+
+	versions, err := cache.versions()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.0.0", "2.0.0"}, versions)
+}
+
+func TestPinnedSpecification(t *testing.T) {
+	server := specificationServer("1.0.0", "2.0.0")
+	defer server.Close()
+	cache, cleanup := createCache(t, server.URL, false, "")
+	defer cleanup()
+
+	assert.Nil(t, cache.pin("1.0.0"))
+	path, err := cache.getSpecificationPath()
+	assert.Nil(t, err)
+	assert.Equal(t, cache.path("1.0.0"), path)
+
+	version := "2.0.0"
+	cache.context.CliArguments.SpecificationVersion = &version
+	path, err = cache.getSpecificationPath()
+	assert.Nil(t, err)
+	assert.Equal(t, cache.path("2.0.0"), path)
+
+	assert.Nil(t, cache.pin(LatestVersion))
+	pinned, _, err := cache.readVersionFile(pinnedFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "", pinned)
+
+	assert.NotNil(t, cache.pin("3.0.0"))
+}
+
+func TestRemoveOldVersions(t *testing.T) {
+	server := specificationServer("1.0.0", "2.0.0", "3.0.0", "4.0.0", "5.0.0", "6.0.0", "7.0.0")
+	defer server.Close()
+	cache, cleanup := createCache(t, server.URL, false, "")
+	defer cleanup()
+
+	assert.Nil(t, cache.pin("1.0.0"))
+	_, err := cache.download("2.0.0")
+	assert.Nil(t, err)
+	_, err = getCompiledSpecification(cache.path("2.0.0"))
+	assert.Nil(t, err)
+	content, err := ioutil.ReadFile(cache.path("2.0.0"))
+	assert.Nil(t, err)
+	compiledPath, err := getCompiledSpecificationPath(content)
+	assert.Nil(t, err)
+	_, err = os.Stat(compiledPath)
+	assert.Nil(t, err)
+
+	for _, version := range []string{"3.0.0", "4.0.0", "5.0.0", "6.0.0", LatestVersion} {
+		_, err = cache.download(version)
+		assert.Nil(t, err)
+	}
+	versions, err := cache.versions()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.0.0", "4.0.0", "5.0.0", "6.0.0", "7.0.0"}, versions)
+	_, err = os.Stat(compiledPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestStoreRemovesCompiledFormOfReplacedSpecification(t *testing.T) {
+	cache, cleanup := createCache(t, "https://replaced.example", true, "")
+	defer cleanup()
+
+	assert.Nil(t, cache.store("1.0.0", []byte(`{"ResourceSpecificationVersion": "1.0.0"}`)))
+	_, err := getCompiledSpecification(cache.path("1.0.0"))
+	assert.Nil(t, err)
+	compiledPath, err := getCompiledSpecificationPath([]byte(`{"ResourceSpecificationVersion": "1.0.0"}`))
+	assert.Nil(t, err)
+	_, err = os.Stat(compiledPath)
+	assert.Nil(t, err)
+
+	assert.Nil(t, cache.store("1.0.0", []byte(`{"ResourceSpecificationVersion": "1.0.0", "ResourceTypes": {}}`)))
+	_, err = os.Stat(compiledPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestInvalidSpecificationVersion(t *testing.T) {
+	cache, cleanup := createCache(t, "https://invalid.example", true, "../../x")
+	defer cleanup()
+
+	_, err := cache.getSpecificationPath()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Invalid specification version ../../x")
+
+	assert.NotNil(t, cache.pin("../x"))
+	assert.Nil(t, cache.writeVersionFile(pinnedFile, "../x"))
+	*cache.context.CliArguments.SpecificationVersion = ""
+	_, err = cache.getSpecificationPath()
+	assert.NotNil(t, err)
+
+	assert.NotNil(t, verifySpecification(Specification{
+		ResourceSpecificationVersion: "../x",
+		ResourceTypes:                map[string]Resource{"AWS::S3::Bucket": {}},
+	}, LatestVersion))
+}
+
+func TestOfflineSpecificationVersionNotCached(t *testing.T) {
+	cache, cleanup := createCache(t, "https://not-cached.example", true, "1.0.0")
+	defer cleanup()
+
+	_, err := cache.getSpecificationPath()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Specification 1.0.0 for region test-region is not cached")
+}
+
+func TestInvalidSpecificationIsNotCached(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(`{"ResourceSpecificationVersion": "1.0.0"}`))
+	}))
+	defer server.Close()
+	cache, cleanup := createCache(t, server.URL, false, "")
+	defer cleanup()
+
+	_, err := cache.getSpecificationPath()
+	assert.NotNil(t, err)
+	assert.False(t, cache.isCached("1.0.0"))
+}
+
+func TestImportLegacySpecification(t *testing.T) {
+	cache, cleanup := createCache(t, "https://legacy.example", true, "")
+	defer cleanup()
+	assert.Nil(t, ioutil.WriteFile(cache.legacyPath, []byte(`{"ResourceSpecificationVersion": "1.2.3"}`), 0644))
+
+	path, err := cache.getSpecificationPath()
+	assert.Nil(t, err)
+	assert.Equal(t, cache.path("1.2.3"), path)
+	_, err = os.Stat(cache.legacyPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.True(t, compareVersions("2.10.0", "2.9.1") > 0)
+	assert.True(t, compareVersions("2.9", "2.9.1") < 0)
+	assert.Equal(t, 0, compareVersions("18.6.0", "18.6.0"))
+}
+
+func TestCacheDirectory(t *testing.T) {
+	cache, cleanup := createCache(t, "https://d1uauaxba7bl26.cloudfront.net", true, "")
+	defer cleanup()
+	assert.Equal(t, "d1uauaxba7bl26", filepath.Base(cache.directory))
+	assert.Equal(t, "d1uauaxba7bl26.json", filepath.Base(cache.legacyPath))
+}
+
+func TestMirrorSpecifications(t *testing.T) {
+	server := specificationServer("1.0.0", "2.0.0")
+	defer server.Close()
+	mirror, err := ioutil.TempDir("", "mirror")
+	assert.Nil(t, err)
+	defer os.RemoveAll(mirror)
+
+	quietLogger := logger.CreateQuietLogger()
+	ctx := &context.Context{Logger: &quietLogger}
+	err = MirrorSpecifications(ctx, map[string]string{"test-region": server.URL}, mirror)
+	assert.Nil(t, err)
+	for _, version := range []string{"2.0.0", LatestVersion} {
+		_, err = os.Stat(filepath.Join(mirror, "test-region", version, "gzip", "CloudFormationResourceSpecification.json"))
+		assert.Nil(t, err)
+	}
+
+	err = MirrorSpecifications(ctx, map[string]string{"other-region": server.URL + "/missing"}, mirror)
+	assert.NotNil(t, err)
+
+	cache, cleanup := createCache(t, filepath.Join(mirror, "test-region"), true, "")
+	defer cleanup()
+	path, err := cache.getSpecificationPath()
+	assert.Nil(t, err)
+	assert.Equal(t, cache.path("2.0.0"), path)
+}
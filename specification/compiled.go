@@ -0,0 +1,169 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package specification
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+)
+
+// Version of the compiled specification format. It's a part of the cache key, so specifications compiled
+// in an older format are not read.
+const compiledFormatVersion = "1"
+
+// compiledSpecification is the form of specification cached in gob format. Property types are encoded one by one,
+// so only the ones used during validation have to be decoded.
+type compiledSpecification struct {
+	ResourceSpecificationVersion string
+	ResourceTypes                map[string]Resource
+	PropertyTypes                map[string][]byte
+}
+
+// propertyTypeIndex contains encoded property types of a compiled specification which haven't been decoded yet.
+type propertyTypeIndex struct {
+	sync.Mutex
+	encoded map[string][]byte
+}
+
+// compiledSpecificationDirectory returns directory where compiled specifications are cached.
+var compiledSpecificationDirectory = func() (string, error) {
+	user, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return user.HomeDir + "/.config/perun/specification/compiled", nil
+}
+
+// GetPropertyType returns the property type of the given name. Property types of specification read from
+// the compiled cache are decoded when they are used for the first time.
+func (specification *Specification) GetPropertyType(name string) (propertyType PropertyType, exists bool) {
+	index := specification.propertyTypes
+	if index == nil {
+		propertyType, exists = specification.PropertyTypes[name]
+		return
+	}
+
+	index.Lock()
+	defer index.Unlock()
+	if propertyType, exists = specification.PropertyTypes[name]; exists {
+		return
+	}
+	encoded, exists := index.encoded[name]
+	if !exists {
+		return
+	}
+	if err := gob.NewDecoder(bytes.NewReader(encoded)).Decode(&propertyType); err != nil {
+		return PropertyType{}, false
+	}
+	specification.PropertyTypes[name] = propertyType
+	delete(index.encoded, name)
+	return propertyType, true
+}
+
+// getCompiledSpecification reads specification cached in cache directory. Its compiled form, identified by hash
+// of the file, is stored next to the other compiled specifications, so next time it's loaded much faster.
+func getCompiledSpecification(specificationFilePath string) (specification Specification, err error) {
+	specificationFile, err := ioutil.ReadFile(specificationFilePath)
+	if err != nil {
+		return specification, err
+	}
+
+	compiledPath, err := getCompiledSpecificationPath(specificationFile)
+	if err != nil {
+		return parseSpecificationFile(specificationFile)
+	}
+	if specification, err = readCompiledSpecification(compiledPath); err == nil {
+		return specification, nil
+	}
+	specification, err = parseSpecificationFile(specificationFile)
+	if err != nil {
+		return specification, err
+	}
+	// The compiled form only speeds up loading, so specification is used even if it can't be cached.
+	writeCompiledSpecification(compiledPath, specification)
+	return specification, nil
+}
+
+// getCompiledSpecificationPath returns path to the compiled form of the specification file, identified by its hash.
+func getCompiledSpecificationPath(specificationFile []byte) (string, error) {
+	directory, err := compiledSpecificationDirectory()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(specificationFile)
+	return filepath.Join(directory, hex.EncodeToString(hash[:])+"-"+compiledFormatVersion+".gob"), nil
+}
+
+// removeCompiledSpecification removes the compiled form of the specification file from the cache, if it exists.
+func removeCompiledSpecification(specificationFile []byte) error {
+	path, err := getCompiledSpecificationPath(specificationFile)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func readCompiledSpecification(path string) (specification Specification, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	var compiled compiledSpecification
+	if err = gob.NewDecoder(bufio.NewReader(file)).Decode(&compiled); err != nil {
+		return
+	}
+	return Specification{
+		PropertyTypes:                make(map[string]PropertyType),
+		ResourceSpecificationVersion: compiled.ResourceSpecificationVersion,
+		ResourceTypes:                compiled.ResourceTypes,
+		propertyTypes:                &propertyTypeIndex{encoded: compiled.PropertyTypes},
+	}, nil
+}
+
+func writeCompiledSpecification(path string, specification Specification) error {
+	compiled := compiledSpecification{
+		ResourceSpecificationVersion: specification.ResourceSpecificationVersion,
+		ResourceTypes:                specification.ResourceTypes,
+		PropertyTypes:                make(map[string][]byte, len(specification.PropertyTypes)),
+	}
+	for name, propertyType := range specification.PropertyTypes {
+		var encoded bytes.Buffer
+		if err := gob.NewEncoder(&encoded).Encode(propertyType); err != nil {
+			return err
+		}
+		compiled.PropertyTypes[name] = encoded.Bytes()
+	}
+
+	var content bytes.Buffer
+	if err := gob.NewEncoder(&content).Encode(compiled); err != nil {
+		return err
+	}
+	return writeFile(path, content.Bytes())
+}
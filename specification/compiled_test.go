@@ -0,0 +1,94 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package specification
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompiledSpecification(t *testing.T) {
+	directory, err := ioutil.TempDir("", "specification")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	defaultDirectory := compiledSpecificationDirectory
+	compiledSpecificationDirectory = func() (string, error) {
+		return filepath.Join(directory, "compiled"), nil
+	}
+	defer func() {
+		compiledSpecificationDirectory = defaultDirectory
+	}()
+	content, err := ioutil.ReadFile("test_resources/test_specification.json")
+	assert.Nil(t, err)
+	specificationPath := filepath.Join(directory, "specification.json")
+	assert.Nil(t, ioutil.WriteFile(specificationPath, content, 0644))
+
+	compiledPath, err := getCompiledSpecificationPath(content)
+	assert.Nil(t, err)
+	_, err = GetSpecificationFromFile(specificationPath)
+	assert.Nil(t, err)
+	_, err = os.Stat(compiledPath)
+	assert.True(t, os.IsNotExist(err))
+
+	parsed, err := getCompiledSpecification(specificationPath)
+	assert.Nil(t, err)
+	assert.Nil(t, parsed.propertyTypes)
+	_, err = os.Stat(compiledPath)
+	assert.Nil(t, err)
+
+	compiled, err := getCompiledSpecification(specificationPath)
+	assert.Nil(t, err)
+	assert.NotNil(t, compiled.propertyTypes)
+	assert.Empty(t, compiled.PropertyTypes)
+	assert.Equal(t, parsed.ResourceSpecificationVersion, compiled.ResourceSpecificationVersion)
+	assert.Equal(t, parsed.ResourceTypes, compiled.ResourceTypes)
+
+	propertyType, exists := compiled.GetPropertyType("ExampleProperties")
+	assert.True(t, exists)
+	assert.Equal(t, parsed.PropertyTypes["ExampleProperties"], propertyType)
+	assert.Len(t, compiled.PropertyTypes, 1)
+	_, exists = compiled.GetPropertyType("MissingProperties")
+	assert.False(t, exists)
+
+	assert.Nil(t, ioutil.WriteFile(specificationPath, []byte(`{"ResourceSpecificationVersion": "2.0.0"}`), 0644))
+	changed, err := getCompiledSpecification(specificationPath)
+	assert.Nil(t, err)
+	assert.Equal(t, "2.0.0", changed.ResourceSpecificationVersion)
+}
+
+func TestGetPropertyTypeConcurrently(t *testing.T) {
+	spec, err := getCompiledSpecification("test_resources/test_specification.json")
+	assert.Nil(t, err)
+	spec, err = getCompiledSpecification("test_resources/test_specification.json")
+	assert.Nil(t, err)
+
+	var wait sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			_, exists := spec.GetPropertyType("ExampleProperties")
+			assert.True(t, exists)
+		}()
+	}
+	wait.Wait()
+}
@@ -0,0 +1,65 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package specification
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+
+	"github.com/Appliscale/perun/context"
+)
+
+// MirrorSpecifications downloads the latest specifications of all regions from the URLs into the directory, so it can
+// be used as SpecificationMirror by perun without internet access. Every region's specification is stored like
+// on the specification URL: <directory>/<region>/latest and <directory>/<region>/<version>.
+func MirrorSpecifications(context *context.Context, urls map[string]string, directory string) error {
+	regions := make([]string, 0, len(urls))
+	for region := range urls {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	failed := 0
+	for _, region := range regions {
+		version, err := mirrorSpecification(urls[region], filepath.Join(directory, region))
+		if err != nil {
+			context.Logger.Error("Could not mirror specification for region " + region + ": " + err.Error())
+			failed++
+			continue
+		}
+		context.Logger.Info("Specification " + version + " for region " + region + " mirrored")
+	}
+	if failed > 0 {
+		return errors.New("Specifications of some regions could not be mirrored")
+	}
+	return nil
+}
+
+func mirrorSpecification(url string, directory string) (string, error) {
+	content, specification, err := fetchSpecification(url+"/"+LatestVersion+"/gzip/CloudFormationResourceSpecification.json", LatestVersion)
+	if err != nil {
+		return "", err
+	}
+	version := specification.ResourceSpecificationVersion
+	for _, name := range []string{version, LatestVersion} {
+		if err := writeFile(filepath.Join(directory, name, "gzip", "CloudFormationResourceSpecification.json"), content); err != nil {
+			return "", err
+		}
+	}
+	return version, nil
+}
@@ -20,21 +20,20 @@ package specification
 
 import (
 	"encoding/json"
-	"io"
 	"io/ioutil"
-	"net/http"
-	"os"
-	"os/user"
-	"strings"
 
 	"github.com/Appliscale/perun/context"
 )
 
 // Specification contains information about specification - type and version.
+// Property types should be read with GetPropertyType, because PropertyTypes of specification read from
+// the compiled cache contains only property types which have been already used.
 type Specification struct {
 	PropertyTypes                map[string]PropertyType
 	ResourceSpecificationVersion string
 	ResourceTypes                map[string]Resource
+	// Property types which haven't been decoded yet, if specification was read from the compiled cache.
+	propertyTypes *propertyTypeIndex
 }
 
 // PropertyType contains Documentation and map of Properties.
@@ -78,17 +77,22 @@ func (property *Property) IsSubproperty() bool {
 	return false
 }
 
-// Download specification for region specified in config.
+// Get specification for region specified in config. The version given with --spec-version, the pinned version
+// or the latest one is used, and it's downloaded if it's not cached.
 func GetSpecification(context *context.Context) (specification Specification, err error) {
-	filePath, err := downloadSpecification(context)
+	cache, err := newCache(context)
+	if err != nil {
+		return specification, err
+	}
+	filePath, err := cache.getSpecificationPath()
 	if err != nil {
 		return specification, err
 	}
 
-	return GetSpecificationFromFile(filePath)
+	return getCompiledSpecification(filePath)
 }
 
-// Get specification from file. It's used in GetSpecification().
+// Get specification from file.
 func GetSpecificationFromFile(specificationFilePath string) (specification Specification, err error) {
 	specificationFile, err := ioutil.ReadFile(specificationFilePath)
 	if err != nil {
@@ -98,48 +102,6 @@ func GetSpecificationFromFile(specificationFilePath string) (specification Speci
 	return parseSpecificationFile(specificationFile)
 }
 
-func downloadSpecification(context *context.Context) (filePath string, err error) {
-	user, err := user.Current()
-	if err != nil {
-		return
-	}
-
-	specificationDir := user.HomeDir + "/.config/perun/specification"
-	specificationFileUrl, err := context.Config.GetSpecificationFileURLForCurrentRegion()
-	if err != nil {
-		return
-	}
-	fileName := strings.Replace(specificationFileUrl, "https://", "", -1)
-	fileName = strings.Replace(fileName, ".cloudfront.net/latest/gzip/CloudFormationResourceSpecification.json", "", -1)
-	specificationFilePath := specificationDir + "/" + fileName + ".json"
-
-	if _, err := os.Stat(specificationFilePath); err == nil {
-		return specificationFilePath, nil
-	}
-
-	if _, err := os.Stat(specificationDir); os.IsNotExist(err) {
-		os.MkdirAll(specificationDir, os.ModePerm)
-	}
-	out, err := os.Create(specificationFilePath)
-	if err != nil {
-		return
-	}
-	defer out.Close()
-
-	resp, err := http.Get(specificationFileUrl)
-	if err != nil {
-		return
-	}
-	defer resp.Body.Close()
-
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
-		return
-	}
-
-	return specificationFilePath, nil
-}
-
 func parseSpecificationFile(specificationFile []byte) (specification Specification, err error) {
 	err = json.Unmarshal(specificationFile, &specification)
 	if err != nil {
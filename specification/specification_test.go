@@ -17,7 +17,12 @@
 package specification
 
 import (
+	"github.com/Appliscale/perun/cliparser"
+	"github.com/Appliscale/perun/configuration"
+	"github.com/Appliscale/perun/context"
+	"github.com/Appliscale/perun/logger"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
 	"os"
 	"testing"
 )
@@ -33,8 +38,16 @@ func setup() {
 }
 
 func TestMain(m *testing.M) {
+	compiledDirectory, err := ioutil.TempDir("", "compiled")
+	if err != nil {
+		panic(err)
+	}
+	compiledSpecificationDirectory = func() (string, error) {
+		return compiledDirectory, nil
+	}
 	setup()
 	retCode := m.Run()
+	os.RemoveAll(compiledDirectory)
 	os.Exit(retCode)
 }
 
@@ -105,3 +118,20 @@ func TestResourceTypes(t *testing.T) {
 func getErrorMessage(fieldName string) string {
 	return "Invalid field " + fieldName
 }
+
+func TestOfflineSpecificationNotCached(t *testing.T) {
+	offline := true
+	quietLogger := logger.CreateQuietLogger()
+	ctx := context.Context{
+		CliArguments: cliparser.CliArguments{Offline: &offline},
+		Logger:       &quietLogger,
+		Config: configuration.Configuration{
+			DefaultRegion:    "test-region",
+			SpecificationURL: map[string]string{"test-region": "https://not-cached.example"},
+		},
+	}
+
+	_, err := GetSpecification(&ctx)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Specification for region test-region is not cached")
+}
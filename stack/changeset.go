@@ -58,7 +58,7 @@ func DeleteChangeSet(ctx *context.Context) (err error) {
 
 // NewChangeSet create change set and gets parameters.
 func NewChangeSet(context *context.Context) (err error) {
-	template, stackName, err := getTemplateFromFile(context)
+	template, stackName, err := getTemplateWithSnippets(context)
 	if err != nil {
 		return
 	}
@@ -41,7 +41,7 @@ func createStackInput(template *string, stackName *string, context *context.Cont
 
 // NewStack create Stack. It's get template from context.CliArguments.TemplatePath.
 func NewStack(context *context.Context) error {
-	template, stackName, incorrectPath := getTemplateFromFile(context)
+	template, stackName, incorrectPath := getTemplateWithSnippets(context)
 	if incorrectPath != nil {
 		context.Logger.Error(incorrectPath.Error())
 		return incorrectPath
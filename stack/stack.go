@@ -23,6 +23,7 @@ import (
 	"io/ioutil"
 
 	"github.com/Appliscale/perun/context"
+	"github.com/Appliscale/perun/helpers"
 	"github.com/Appliscale/perun/myuser"
 )
 
@@ -47,6 +48,20 @@ func getTemplateFromFile(context *context.Context) (string, string, error) {
 	return template, stackName, nil
 }
 
+// This function reads template like getTemplateFromFile and includes snippets of AWS::Include transforms into it.
+func getTemplateWithSnippets(context *context.Context) (string, string, error) {
+	template, stackName, err := getTemplateFromFile(context)
+	if err != nil {
+		return "", "", err
+	}
+	rawTemplate, err := helpers.IncludeSnippets(*context.CliArguments.TemplatePath, []byte(template), context.DownloadSnippet, context.Logger)
+	if err != nil {
+		context.Logger.Error(err.Error())
+		return "", "", err
+	}
+	return string(rawTemplate), stackName, nil
+}
+
 // Looking for path to user/default template.
 func getPath(context *context.Context) (path string, err error) {
 	homePath, pathError := myuser.GetUserHomeDir()
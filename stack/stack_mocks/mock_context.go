@@ -22,12 +22,14 @@ func SetupContext(t *testing.T, args []string) *context.Context {
 
 	config := createDefaultConfiguration()
 	iconsistenciesConfig := configuration.ReadInconsistencyConfiguration(&myLogger)
+	restrictionsConfig := configuration.ReadRestrictionsConfiguration(&myLogger)
 
 	ctx := context.Context{
 		CliArguments:        cliArguments,
 		Logger:              &myLogger,
 		Config:              config,
 		InconsistencyConfig: iconsistenciesConfig,
+		RestrictionsConfig:  restrictionsConfig,
 	}
 
 	return &ctx
@@ -25,7 +25,7 @@ import (
 
 // UpdateStack prepares updateStackInput and updates stack.
 func UpdateStack(context *context.Context) (err error) {
-	template, stackName, err := getTemplateFromFile(context)
+	template, stackName, err := getTemplateWithSnippets(context)
 	if err != nil {
 		return
 	}
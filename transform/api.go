@@ -0,0 +1,241 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"errors"
+	"sort"
+)
+
+// Properties of AWS::Serverless::Api which are the same as properties of AWS::ApiGateway::RestApi.
+var restAPIProperties = []string{
+	"BinaryMediaTypes",
+	"Description",
+	"DisableExecuteApiEndpoint",
+	"FailOnWarnings",
+	"MinimumCompressionSize",
+	"Mode",
+	"Name",
+}
+
+// Properties of AWS::Serverless::Api which are the same as properties of AWS::ApiGateway::Stage.
+var restAPIStageProperties = []string{
+	"AccessLogSetting",
+	"CacheClusterEnabled",
+	"CacheClusterSize",
+	"CanarySetting",
+	"MethodSettings",
+	"TracingEnabled",
+	"Variables",
+}
+
+// expandRestAPI translates the API into REST API with a deployment and a stage. If the API has no definition,
+// it is created from Api events of functions.
+func (expander *serverlessExpander) expandRestAPI(name string, resource map[string]interface{}) error {
+	properties := mergeGlobals(getProperties(resource), expander.globals["Api"])
+	stageName, hasStageName := properties["StageName"]
+	if !hasStageName {
+		return errors.New("StageName is required")
+	}
+
+	restAPI := make(map[string]interface{})
+	copyProperties(properties, restAPI, restAPIProperties...)
+	if err := setAPIDefinition(restAPI, properties, swaggerDefinition(expander.restAPIPaths[name])); err != nil {
+		return err
+	}
+	switch endpoint := properties["EndpointConfiguration"].(type) {
+	case string:
+		restAPI["EndpointConfiguration"] = map[string]interface{}{"Types": []interface{}{endpoint}}
+	case map[string]interface{}:
+		configuration := map[string]interface{}{"Types": []interface{}{endpoint["Type"]}}
+		if endpointIds, ok := endpoint["VPCEndpointIds"]; ok {
+			configuration["VpcEndpointIds"] = endpointIds
+		}
+		restAPI["EndpointConfiguration"] = configuration
+	}
+	resource["Type"] = "AWS::ApiGateway::RestApi"
+	resource["Properties"] = restAPI
+
+	deploymentName := name + "Deployment"
+	if err := expander.addResource(deploymentName, newResource("AWS::ApiGateway::Deployment", map[string]interface{}{"RestApiId": ref(name)}), resource); err != nil {
+		return err
+	}
+	stage := map[string]interface{}{
+		"RestApiId":    ref(name),
+		"DeploymentId": ref(deploymentName),
+		"StageName":    stageName,
+	}
+	copyProperties(properties, stage, restAPIStageProperties...)
+	stageResourceName := name + "Stage"
+	if stageString, isString := stageName.(string); isString {
+		stageResourceName = name + stageString + "Stage"
+	}
+	return expander.addResource(stageResourceName, newResource("AWS::ApiGateway::Stage", stage), resource)
+}
+
+// expandHTTPAPI translates the API into HTTP API with a stage. If the API has no definition, it is created
+// from HttpApi events of functions.
+func (expander *serverlessExpander) expandHTTPAPI(name string, resource map[string]interface{}) error {
+	properties := mergeGlobals(getProperties(resource), expander.globals["HttpApi"])
+	httpAPI := make(map[string]interface{})
+	copyProperties(properties, httpAPI, "Description", "DisableExecuteApiEndpoint", "FailOnWarnings")
+	if err := setAPIDefinition(httpAPI, properties, openAPIDefinition(expander.httpAPIPaths[name])); err != nil {
+		return err
+	}
+	tags := map[string]interface{}{"httpapi:createdBy": "SAM"}
+	if apiTags, ok := properties["Tags"].(map[string]interface{}); ok {
+		for key, value := range apiTags {
+			tags[key] = value
+		}
+	}
+	httpAPI["Tags"] = tags
+	resource["Type"] = "AWS::ApiGatewayV2::Api"
+	resource["Properties"] = httpAPI
+
+	stageName, hasStageName := properties["StageName"]
+	if !hasStageName {
+		stageName = "$default"
+	}
+	stage := map[string]interface{}{
+		"ApiId":      ref(name),
+		"StageName":  stageName,
+		"AutoDeploy": true,
+	}
+	copyProperties(properties, stage, "AccessLogSettings", "DefaultRouteSettings", "RouteSettings", "StageVariables")
+	stageResourceName := name + "Stage"
+	if stageString, isString := stageName.(string); isString {
+		stageResourceName = name + stageString + "Stage"
+	}
+	if stageName == "$default" {
+		stageResourceName = name + "ApiGatewayDefaultStage"
+	}
+	return expander.addResource(stageResourceName, newResource("AWS::ApiGatewayV2::Stage", stage), resource)
+}
+
+// setAPIDefinition sets Body or BodyS3Location of the API from DefinitionBody or DefinitionUri. If none of them
+// is set, the generated definition is used.
+func setAPIDefinition(api map[string]interface{}, properties map[string]interface{}, generated map[string]interface{}) error {
+	if body, ok := properties["DefinitionBody"]; ok {
+		api["Body"] = body
+	} else if definitionURI, ok := properties["DefinitionUri"]; ok {
+		location, err := s3Location(definitionURI, "Bucket", "Key", "Version")
+		if err != nil {
+			return err
+		}
+		api["BodyS3Location"] = location
+	} else {
+		api["Body"] = generated
+	}
+	return nil
+}
+
+// addImplicitAPIs creates APIs for events of functions which don't refer to any API.
+func (expander *serverlessExpander) addImplicitAPIs() error {
+	for _, name := range sortedAPINames(expander.restAPIPaths) {
+		paths := expander.restAPIPaths[name]
+		if name != implicitRestAPI {
+			return errors.New("Api events refer to " + name + ", which is not AWS::Serverless::Api declared in the template")
+		}
+		if len(paths) > 0 {
+			api := newResource("AWS::Serverless::Api", map[string]interface{}{"StageName": "Prod"})
+			if err := expander.addResource(name, api, map[string]interface{}{}); err != nil {
+				return err
+			}
+			if err := expander.expandRestAPI(name, api); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range sortedAPINames(expander.httpAPIPaths) {
+		paths := expander.httpAPIPaths[name]
+		if name != implicitHTTPAPI {
+			return errors.New("HttpApi events refer to " + name + ", which is not AWS::Serverless::HttpApi declared in the template")
+		}
+		if len(paths) > 0 {
+			api := newResource("AWS::Serverless::HttpApi", map[string]interface{}{})
+			if err := expander.addResource(name, api, map[string]interface{}{}); err != nil {
+				return err
+			}
+			if err := expander.expandHTTPAPI(name, api); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func sortedAPINames(apiPaths map[string][]apiPath) []string {
+	names := make([]string, 0, len(apiPaths))
+	for name := range apiPaths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lambdaIntegration returns integration of API Gateway which invokes the function with a proxy request.
+func lambdaIntegration(functionName string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "aws_proxy",
+		"httpMethod": "POST",
+		"uri":        sub("arn:${AWS::Partition}:apigateway:${AWS::Region}:lambda:path/2015-03-31/functions/${"+functionName+".Arn}/invocations", nil),
+	}
+}
+
+func definitionPaths(paths []apiPath, integration func(path apiPath) map[string]interface{}) map[string]interface{} {
+	definition := make(map[string]interface{})
+	for _, path := range paths {
+		methods, ok := definition[path.Path].(map[string]interface{})
+		if !ok {
+			methods = make(map[string]interface{})
+			definition[path.Path] = methods
+		}
+		method := path.Method
+		if method == "any" {
+			method = "x-amazon-apigateway-any-method"
+		}
+		methods[method] = map[string]interface{}{
+			"x-amazon-apigateway-integration": integration(path),
+			"responses":                       map[string]interface{}{},
+		}
+	}
+	return definition
+}
+
+// swaggerDefinition creates Swagger definition of REST API with the paths.
+func swaggerDefinition(paths []apiPath) map[string]interface{} {
+	return map[string]interface{}{
+		"swagger": "2.0",
+		"info":    map[string]interface{}{"version": "1.0", "title": ref("AWS::StackName")},
+		"paths": definitionPaths(paths, func(path apiPath) map[string]interface{} {
+			return lambdaIntegration(path.Function)
+		}),
+	}
+}
+
+// openAPIDefinition creates OpenAPI definition of HTTP API with the paths.
+func openAPIDefinition(paths []apiPath) map[string]interface{} {
+	return map[string]interface{}{
+		"openapi": "3.0.1",
+		"info":    map[string]interface{}{"version": "1.0", "title": ref("AWS::StackName")},
+		"paths": definitionPaths(paths, func(path apiPath) map[string]interface{} {
+			integration := lambdaIntegration(path.Function)
+			integration["payloadFormatVersion"] = "2.0"
+			return integration
+		}),
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Managed policies which execution roles of functions need to read from event sources.
+var eventSourcePolicies = map[string]string{
+	"SQS":      "service-role/AWSLambdaSQSQueueExecutionRole",
+	"Kinesis":  "service-role/AWSLambdaKinesisExecutionRole",
+	"DynamoDB": "service-role/AWSLambdaDynamoDBExecutionRole",
+	"MSK":      "service-role/AWSLambdaMSKExecutionRole",
+}
+
+// Properties of event sources which point at the source of events.
+var eventSourceProperties = map[string]string{
+	"SQS":      "Queue",
+	"Kinesis":  "Stream",
+	"DynamoDB": "Stream",
+	"MSK":      "Stream",
+}
+
+// expandEvents adds resources which invoke the function on its events. It returns managed policies
+// which the execution role of the function needs.
+func (expander *serverlessExpander) expandEvents(functionName string, function map[string]interface{}, events interface{}) (policies []string, err error) {
+	eventsMap, _ := events.(map[string]interface{})
+	for _, eventName := range sortedKeys(eventsMap) {
+		event, isMap := eventsMap[eventName].(map[string]interface{})
+		if !isMap {
+			return nil, errors.New("Event " + eventName + " has to be a map")
+		}
+		eventType, _ := event["Type"].(string)
+		properties, _ := event["Properties"].(map[string]interface{})
+		if properties == nil {
+			properties = make(map[string]interface{})
+		}
+		id := functionName + eventName
+
+		switch eventType {
+		case "S3":
+			err = expander.expandS3Event(id, functionName, function, properties)
+		case "SNS":
+			err = expander.expandSNSEvent(id, functionName, function, properties)
+		case "SQS", "Kinesis", "DynamoDB", "MSK":
+			err = expander.expandEventSourceMapping(id, eventType, functionName, function, properties)
+			policies = append(policies, eventSourcePolicies[eventType])
+		case "Schedule", "CloudWatchEvent", "EventBridgeRule":
+			err = expander.expandRuleEvent(id, eventType, functionName, function, properties)
+		case "CloudWatchLogs":
+			err = expander.expandCloudWatchLogsEvent(id, functionName, function, properties)
+		case "Api":
+			err = expander.expandAPIEvent(id, functionName, function, properties)
+		case "HttpApi":
+			err = expander.expandHTTPAPIEvent(id, functionName, function, properties)
+		default:
+			expander.logger.Warning("Event " + eventName + " of type " + eventType + " of function " + functionName + " is not supported by the local expansion of " + ServerlessTransform + " transform")
+		}
+		if err != nil {
+			return nil, errors.New("Event " + eventName + ": " + err.Error())
+		}
+	}
+	return
+}
+
+// addPermission adds permission for the service to invoke the function. It returns properties of the permission.
+func (expander *serverlessExpander) addPermission(name string, functionName string, function map[string]interface{}, principal string, sourceArn interface{}) (map[string]interface{}, error) {
+	permission := map[string]interface{}{
+		"Action":       "lambda:InvokeFunction",
+		"FunctionName": ref(functionName),
+		"Principal":    principal,
+	}
+	if sourceArn != nil {
+		permission["SourceArn"] = sourceArn
+	}
+	return permission, expander.addResource(name, newResource("AWS::Lambda::Permission", permission), function)
+}
+
+// expandS3Event adds notifications of the function to the bucket, which has to be declared in the template.
+func (expander *serverlessExpander) expandS3Event(id string, functionName string, function map[string]interface{}, properties map[string]interface{}) error {
+	bucketRef, _ := properties["Bucket"].(map[string]interface{})
+	bucketName, isRef := getRefTarget(bucketRef)
+	bucket, isBucket := expander.resources[bucketName].(map[string]interface{})
+	if !isRef || !isBucket || bucket["Type"] != "AWS::S3::Bucket" {
+		return errors.New("Bucket has to be a Ref to AWS::S3::Bucket declared in the template")
+	}
+
+	permissionName := id + "Permission"
+	permission, err := expander.addPermission(permissionName, functionName, function, "s3.amazonaws.com", nil)
+	if err != nil {
+		return err
+	}
+	permission["SourceAccount"] = ref("AWS::AccountId")
+
+	events := properties["Events"]
+	if _, isList := events.([]interface{}); !isList {
+		events = []interface{}{events}
+	}
+	var configurations []interface{}
+	for _, event := range events.([]interface{}) {
+		configuration := map[string]interface{}{"Event": event, "Function": getAtt(functionName, "Arn")}
+		copyProperties(properties, configuration, "Filter")
+		configurations = append(configurations, configuration)
+	}
+
+	bucketProperties := getProperties(bucket)
+	bucket["Properties"] = bucketProperties
+	notifications, _ := bucketProperties["NotificationConfiguration"].(map[string]interface{})
+	if notifications == nil {
+		notifications = make(map[string]interface{})
+		bucketProperties["NotificationConfiguration"] = notifications
+	}
+	existing, _ := notifications["LambdaConfigurations"].([]interface{})
+	notifications["LambdaConfigurations"] = append(existing, configurations...)
+	addDependsOn(bucket, permissionName)
+	return nil
+}
+
+func (expander *serverlessExpander) expandSNSEvent(id string, functionName string, function map[string]interface{}, properties map[string]interface{}) error {
+	subscription := map[string]interface{}{
+		"Endpoint": getAtt(functionName, "Arn"),
+		"Protocol": "lambda",
+		"TopicArn": properties["Topic"],
+	}
+	copyProperties(properties, subscription, "FilterPolicy", "Region")
+	if err := expander.addResource(id, newResource("AWS::SNS::Subscription", subscription), function); err != nil {
+		return err
+	}
+	_, err := expander.addPermission(id+"Permission", functionName, function, "sns.amazonaws.com", properties["Topic"])
+	return err
+}
+
+// expandEventSourceMapping adds mapping of the queue or the stream to the function. Properties of the event
+// are the same as properties of the mapping, except the source of events.
+func (expander *serverlessExpander) expandEventSourceMapping(id string, eventType string, functionName string, function map[string]interface{}, properties map[string]interface{}) error {
+	mapping := map[string]interface{}{"FunctionName": ref(functionName)}
+	for name, value := range properties {
+		if name == eventSourceProperties[eventType] {
+			mapping["EventSourceArn"] = value
+		} else {
+			mapping[name] = value
+		}
+	}
+	return expander.addResource(id, newResource("AWS::Lambda::EventSourceMapping", mapping), function)
+}
+
+// expandRuleEvent adds EventBridge (CloudWatch Events) rule which targets the function.
+func (expander *serverlessExpander) expandRuleEvent(id string, eventType string, functionName string, function map[string]interface{}, properties map[string]interface{}) error {
+	target := map[string]interface{}{"Arn": getAtt(functionName, "Arn"), "Id": id + "LambdaTarget"}
+	copyProperties(properties, target, "Input", "InputPath")
+	rule := map[string]interface{}{"Targets": []interface{}{target}}
+	copyProperties(properties, rule, "Description", "EventBusName", "Name", "State")
+	if eventType == "Schedule" {
+		rule["ScheduleExpression"] = properties["Schedule"]
+	} else {
+		rule["EventPattern"] = properties["Pattern"]
+	}
+	if enabled, ok := properties["Enabled"].(bool); ok {
+		rule["State"] = map[bool]string{true: "ENABLED", false: "DISABLED"}[enabled]
+	}
+	if err := expander.addResource(id, newResource("AWS::Events::Rule", rule), function); err != nil {
+		return err
+	}
+	_, err := expander.addPermission(id+"Permission", functionName, function, "events.amazonaws.com", getAtt(id, "Arn"))
+	return err
+}
+
+func (expander *serverlessExpander) expandCloudWatchLogsEvent(id string, functionName string, function map[string]interface{}, properties map[string]interface{}) error {
+	permissionName := id + "Permission"
+	sourceArn := sub("arn:${AWS::Partition}:logs:${AWS::Region}:${AWS::AccountId}:log-group:${__LogGroupName__}:*",
+		map[string]interface{}{"__LogGroupName__": properties["LogGroupName"]})
+	if _, err := expander.addPermission(permissionName, functionName, function, "logs.amazonaws.com", sourceArn); err != nil {
+		return err
+	}
+	filter := map[string]interface{}{"DestinationArn": getAtt(functionName, "Arn")}
+	copyProperties(properties, filter, "FilterPattern", "LogGroupName")
+	subscriptionFilter := newResource("AWS::Logs::SubscriptionFilter", filter)
+	addDependsOn(subscriptionFilter, permissionName)
+	return expander.addResource(id, subscriptionFilter, function)
+}
+
+// apiPath is a path of API which invokes a function.
+type apiPath struct {
+	Path     string
+	Method   string
+	Function string
+}
+
+var pathParameterRegex = regexp.MustCompile(`\{[^}]*\}`)
+
+// executeAPIArn returns ARN of API methods, which are allowed to invoke the function.
+func executeAPIArn(apiName string, path string, method string) interface{} {
+	resource := "*"
+	if path != "$default" {
+		if method == "any" {
+			method = "*"
+		}
+		resource = "*/" + strings.ToUpper(method) + pathParameterRegex.ReplaceAllString(path, "*")
+	}
+	return sub("arn:${AWS::Partition}:execute-api:${AWS::Region}:${AWS::AccountId}:${__ApiId__}/"+resource,
+		map[string]interface{}{"__ApiId__": ref(apiName)})
+}
+
+func (expander *serverlessExpander) expandAPIEvent(id string, functionName string, function map[string]interface{}, properties map[string]interface{}) error {
+	apiName := implicitRestAPI
+	if restAPIID, hasAPI := properties["RestApiId"]; hasAPI {
+		var isRef bool
+		if apiName, isRef = getRefTarget(restAPIID); !isRef {
+			return errors.New("RestApiId has to be a Ref to AWS::Serverless::Api declared in the template")
+		}
+	}
+	path, isPathString := properties["Path"].(string)
+	method, isMethodString := properties["Method"].(string)
+	if !isPathString || !isMethodString {
+		return errors.New("Path and Method have to be strings")
+	}
+	method = strings.ToLower(method)
+
+	expander.restAPIPaths[apiName] = append(expander.restAPIPaths[apiName], apiPath{Path: path, Method: method, Function: functionName})
+	_, err := expander.addPermission(id+"Permission", functionName, function, "apigateway.amazonaws.com", executeAPIArn(apiName, path, method))
+	return err
+}
+
+func (expander *serverlessExpander) expandHTTPAPIEvent(id string, functionName string, function map[string]interface{}, properties map[string]interface{}) error {
+	apiName := implicitHTTPAPI
+	if apiID, hasAPI := properties["ApiId"]; hasAPI {
+		var isRef bool
+		if apiName, isRef = getRefTarget(apiID); !isRef {
+			return errors.New("ApiId has to be a Ref to AWS::Serverless::HttpApi declared in the template")
+		}
+	}
+	path, method := "$default", "any"
+	if value, hasPath := properties["Path"]; hasPath {
+		var isString bool
+		if path, isString = value.(string); !isString {
+			return errors.New("Path has to be a string")
+		}
+	}
+	if value, hasMethod := properties["Method"]; hasMethod {
+		var isString bool
+		if method, isString = value.(string); !isString {
+			return errors.New("Method has to be a string")
+		}
+		method = strings.ToLower(method)
+	}
+
+	expander.httpAPIPaths[apiName] = append(expander.httpAPIPaths[apiName], apiPath{Path: path, Method: method, Function: functionName})
+	_, err := expander.addPermission(id+"Permission", functionName, function, "apigateway.amazonaws.com", executeAPIArn(apiName, path, method))
+	return err
+}
@@ -0,0 +1,171 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"errors"
+)
+
+// IncludeTransform is the transform which includes snippets stored in files.
+const IncludeTransform = "AWS::Include"
+
+// SnippetLoader loads and parses the snippet stored in the location.
+type SnippetLoader func(location string) (interface{}, error)
+
+// includeExpander replaces AWS::Include transforms with snippets.
+type includeExpander struct {
+	load SnippetLoader
+	// Locations of snippets which are being included, to find snippets which include themselves.
+	locations []string
+}
+
+// ExpandIncludes replaces AWS::Include transforms with snippets they refer to. Snippets included in the Transform
+// section are merged into the template. Snippets included with Fn::Transform are merged into the map which contains
+// the function, or replace the map if the function is its only key. Snippets can include other snippets.
+func ExpandIncludes(tmpl map[string]interface{}, load SnippetLoader) error {
+	expander := includeExpander{load: load}
+
+	transforms, isList := tmpl["Transform"].([]interface{})
+	if !isList {
+		transforms = []interface{}{tmpl["Transform"]}
+	}
+	var remaining []interface{}
+	for _, transform := range transforms {
+		parameters, isInclude := getIncludeParameters(transform)
+		if !isInclude {
+			remaining = append(remaining, transform)
+			continue
+		}
+		snippet, err := expander.loadSnippet(parameters)
+		if err != nil {
+			return err
+		}
+		snippetMap, isMap := snippet.(map[string]interface{})
+		if !isMap {
+			return errors.New("Snippet included in the Transform section has to be a map of template sections")
+		}
+		if err := mergeSections(tmpl, snippetMap); err != nil {
+			return err
+		}
+	}
+	if len(remaining) == 0 || (len(remaining) == 1 && remaining[0] == nil) {
+		delete(tmpl, "Transform")
+	} else if isList {
+		tmpl["Transform"] = remaining
+	}
+
+	_, err := expander.expand(tmpl)
+	return err
+}
+
+// getIncludeParameters returns Parameters of the transform, if it's AWS::Include.
+func getIncludeParameters(transform interface{}) (interface{}, bool) {
+	transformMap, isMap := transform.(map[string]interface{})
+	if !isMap || transformMap["Name"] != IncludeTransform {
+		return nil, false
+	}
+	return transformMap["Parameters"], true
+}
+
+func (expander *includeExpander) loadSnippet(parameters interface{}) (interface{}, error) {
+	parametersMap, _ := parameters.(map[string]interface{})
+	location, isString := parametersMap["Location"].(string)
+	if !isString {
+		return nil, errors.New("Location of " + IncludeTransform + " transform has to be a string")
+	}
+	for _, included := range expander.locations {
+		if included == location {
+			return nil, errors.New("Snippet " + location + " includes itself")
+		}
+	}
+	snippet, err := expander.load(location)
+	if err != nil {
+		return nil, errors.New("Could not include snippet " + location + ": " + err.Error())
+	}
+
+	expander.locations = append(expander.locations, location)
+	defer func() {
+		expander.locations = expander.locations[:len(expander.locations)-1]
+	}()
+	return expander.expand(snippet)
+}
+
+func (expander *includeExpander) expand(value interface{}) (interface{}, error) {
+	switch element := value.(type) {
+	case map[string]interface{}:
+		if parameters, isInclude := getIncludeParameters(element["Fn::Transform"]); isInclude {
+			snippet, err := expander.loadSnippet(parameters)
+			if err != nil {
+				return nil, err
+			}
+			if len(element) == 1 {
+				return snippet, nil
+			}
+			snippetMap, isMap := snippet.(map[string]interface{})
+			if !isMap {
+				return nil, errors.New("Snippet included into a map with other keys has to be a map")
+			}
+			delete(element, "Fn::Transform")
+			for key, snippetValue := range snippetMap {
+				if _, exists := element[key]; exists {
+					return nil, errors.New("Snippet " + parameters.(map[string]interface{})["Location"].(string) + " redefines " + key)
+				}
+				element[key] = snippetValue
+			}
+		}
+		for key, child := range element {
+			expanded, err := expander.expand(child)
+			if err != nil {
+				return nil, err
+			}
+			element[key] = expanded
+		}
+	case []interface{}:
+		for index, child := range element {
+			expanded, err := expander.expand(child)
+			if err != nil {
+				return nil, err
+			}
+			element[index] = expanded
+		}
+	}
+	return value, nil
+}
+
+// mergeSections merges sections of the snippet into the template. Elements of sections which are maps are merged,
+// but they can't be redefined.
+func mergeSections(tmpl map[string]interface{}, snippet map[string]interface{}) error {
+	for section, value := range snippet {
+		existing, exists := tmpl[section]
+		if !exists || existing == nil {
+			tmpl[section] = value
+			continue
+		}
+		existingMap, isExistingMap := existing.(map[string]interface{})
+		valueMap, isValueMap := value.(map[string]interface{})
+		if !isExistingMap || !isValueMap {
+			return errors.New("Included snippet redefines " + section + " section")
+		}
+		for name, element := range valueMap {
+			if _, isDefined := existingMap[name]; isDefined {
+				return errors.New("Included snippet redefines " + name + " in " + section + " section")
+			}
+			existingMap[name] = element
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func snippets(t *testing.T, files map[string]string) SnippetLoader {
+	return func(location string) (interface{}, error) {
+		body, exists := files[location]
+		if !exists {
+			return nil, errors.New("file not found")
+		}
+		var snippet interface{}
+		assert.Nil(t, yaml.Unmarshal([]byte(body), &snippet))
+		return snippet, nil
+	}
+}
+
+func includeTemplate(t *testing.T, body string, files map[string]string) (map[string]interface{}, error) {
+	var tmpl map[string]interface{}
+	assert.Nil(t, yaml.Unmarshal([]byte(body), &tmpl))
+	return tmpl, ExpandIncludes(tmpl, snippets(t, files))
+}
+
+func TestExpandIncludeFunction(t *testing.T) {
+	tmpl, err := includeTemplate(t, `
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      Fn::Transform:
+        Name: AWS::Include
+        Parameters:
+          Location: bucket.yaml
+      BucketName: logs
+  Queue:
+    Fn::Transform:
+      Name: AWS::Include
+      Parameters:
+        Location: queue.yaml
+`, map[string]string{
+		"bucket.yaml": "VersioningConfiguration:\n  Status: Enabled\n",
+		"queue.yaml":  "Type: AWS::SQS::Queue\n",
+	})
+	assert.Nil(t, err)
+	resources := tmpl["Resources"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"BucketName":              "logs",
+		"VersioningConfiguration": map[string]interface{}{"Status": "Enabled"},
+	}, resources["Bucket"].(map[string]interface{})["Properties"])
+	assert.Equal(t, map[string]interface{}{"Type": "AWS::SQS::Queue"}, resources["Queue"])
+}
+
+func TestExpandIncludeInTransformSection(t *testing.T) {
+	tmpl, err := includeTemplate(t, `
+Transform:
+  - Name: AWS::Include
+    Parameters:
+      Location: common.yaml
+  - AWS::Serverless-2016-10-31
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+`, map[string]string{
+		"common.yaml": "Parameters:\n  Stage:\n    Type: String\nResources:\n  Queue:\n    Type: AWS::SQS::Queue\n",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"AWS::Serverless-2016-10-31"}, tmpl["Transform"])
+	assert.Contains(t, tmpl["Parameters"], "Stage")
+	assert.Contains(t, tmpl["Resources"], "Bucket")
+	assert.Contains(t, tmpl["Resources"], "Queue")
+
+	tmpl, err = includeTemplate(t, `
+Transform:
+  Name: AWS::Include
+  Parameters:
+    Location: common.yaml
+`, map[string]string{"common.yaml": "Resources:\n  Queue:\n    Type: AWS::SQS::Queue\n"})
+	assert.Nil(t, err)
+	assert.NotContains(t, tmpl, "Transform")
+	assert.Contains(t, tmpl["Resources"], "Queue")
+}
+
+func TestExpandNestedIncludes(t *testing.T) {
+	tmpl, err := includeTemplate(t, `
+Resources:
+  Fn::Transform:
+    Name: AWS::Include
+    Parameters:
+      Location: resources.yaml
+`, map[string]string{
+		"resources.yaml": "Queue:\n  Fn::Transform:\n    Name: AWS::Include\n    Parameters:\n      Location: queue.yaml\n",
+		"queue.yaml":     "Type: AWS::SQS::Queue\n",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"Queue": map[string]interface{}{"Type": "AWS::SQS::Queue"}}, tmpl["Resources"])
+}
+
+func TestInvalidIncludes(t *testing.T) {
+	_, err := includeTemplate(t, `
+Resources:
+  Fn::Transform:
+    Name: AWS::Include
+    Parameters:
+      Location: loop.yaml
+`, map[string]string{
+		"loop.yaml": "Fn::Transform:\n  Name: AWS::Include\n  Parameters:\n    Location: loop.yaml\n",
+	})
+	assert.EqualError(t, err, "Snippet loop.yaml includes itself")
+
+	_, err = includeTemplate(t, `
+Resources:
+  Fn::Transform:
+    Name: AWS::Include
+    Parameters:
+      Location: missing.yaml
+`, map[string]string{})
+	assert.EqualError(t, err, "Could not include snippet missing.yaml: file not found")
+
+	_, err = includeTemplate(t, `
+Transform:
+  Name: AWS::Include
+  Parameters:
+    Location: common.yaml
+Resources:
+  Queue:
+    Type: AWS::SQS::Queue
+`, map[string]string{"common.yaml": "Resources:\n  Queue:\n    Type: AWS::SQS::Queue\n"})
+	assert.EqualError(t, err, "Included snippet redefines Queue in Resources section")
+
+	_, err = includeTemplate(t, `
+Resources:
+  Fn::Transform:
+    Name: AWS::Include
+    Parameters:
+      Location:
+        Ref: SnippetLocation
+`, map[string]string{})
+	assert.EqualError(t, err, "Location of AWS::Include transform has to be a string")
+}
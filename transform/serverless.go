@@ -0,0 +1,375 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Appliscale/perun/logger"
+)
+
+// ServerlessTransform is the transform of AWS Serverless Application Model (SAM) templates.
+const ServerlessTransform = "AWS::Serverless-2016-10-31"
+
+// Logical IDs of APIs created for Api and HttpApi events of functions which don't refer to any API.
+const (
+	implicitRestAPI = "ServerlessRestApi"
+	implicitHTTPAPI = "ServerlessHttpApi"
+)
+
+// serverlessExpander translates resources of the serverless transform into CloudFormation resources.
+type serverlessExpander struct {
+	resources map[string]interface{}
+	globals   map[string]interface{}
+	// Paths of APIs (by logical ID) added by Api and HttpApi events of functions.
+	restAPIPaths map[string][]apiPath
+	httpAPIPaths map[string][]apiPath
+	logger       logger.LoggerInt
+}
+
+// ExpandServerless expands the serverless transform - it translates AWS::Serverless::Function, Api, HttpApi,
+// SimpleTable, LayerVersion and Application resources, with events of functions, into the CloudFormation resources
+// they are deployed as. Global properties from the Globals section are applied. Resources which are not supported
+// are left unchanged and a warning is logged.
+func ExpandServerless(tmpl map[string]interface{}, logger logger.LoggerInt) error {
+	resources, ok := tmpl["Resources"].(map[string]interface{})
+	if !ok {
+		return errors.New("Resources of the template have to be a map")
+	}
+	globals, _ := tmpl["Globals"].(map[string]interface{})
+	expander := serverlessExpander{
+		resources:    resources,
+		globals:      globals,
+		restAPIPaths: make(map[string][]apiPath),
+		httpAPIPaths: make(map[string][]apiPath),
+		logger:       logger,
+	}
+
+	serverlessResources := make(map[string]string)
+	for _, name := range sortedKeys(resources) {
+		if resource, isMap := resources[name].(map[string]interface{}); isMap {
+			if resourceType, isString := resource["Type"].(string); isString && strings.HasPrefix(resourceType, "AWS::Serverless::") {
+				serverlessResources[name] = resourceType
+			}
+		}
+	}
+
+	// Functions are expanded first, because their events add paths to APIs.
+	for _, name := range sortedServerlessResources(serverlessResources, "AWS::Serverless::Function") {
+		if err := expander.expandFunction(name, resources[name].(map[string]interface{})); err != nil {
+			return errors.New("Could not expand function " + name + ": " + err.Error())
+		}
+	}
+	for _, name := range sortedServerlessResources(serverlessResources, "") {
+		resource := resources[name].(map[string]interface{})
+		var err error
+		switch serverlessResources[name] {
+		case "AWS::Serverless::Function":
+			continue
+		case "AWS::Serverless::Api":
+			err = expander.expandRestAPI(name, resource)
+			delete(expander.restAPIPaths, name)
+		case "AWS::Serverless::HttpApi":
+			err = expander.expandHTTPAPI(name, resource)
+			delete(expander.httpAPIPaths, name)
+		case "AWS::Serverless::SimpleTable":
+			err = expander.expandSimpleTable(resource)
+		case "AWS::Serverless::LayerVersion":
+			err = expander.expandLayerVersion(resource)
+		case "AWS::Serverless::Application":
+			err = expander.expandApplication(name, resource)
+		default:
+			logger.Warning("Resource " + name + " of type " + serverlessResources[name] + " is not supported by the local expansion of " + ServerlessTransform + " transform")
+		}
+		if err != nil {
+			return errors.New("Could not expand resource " + name + ": " + err.Error())
+		}
+	}
+	if err := expander.addImplicitAPIs(); err != nil {
+		return err
+	}
+
+	delete(tmpl, "Globals")
+	removeTransform(tmpl, ServerlessTransform)
+	return nil
+}
+
+// sortedServerlessResources returns names of serverless resources of the type (or all if it's empty) in order.
+func sortedServerlessResources(serverlessResources map[string]string, resourceType string) []string {
+	names := make(map[string]interface{})
+	for name, serverlessType := range serverlessResources {
+		if resourceType == "" || serverlessType == resourceType {
+			names[name] = serverlessType
+		}
+	}
+	return sortedKeys(names)
+}
+
+// addResource adds the resource generated for the source resource. The resource is created only if the source
+// resource is created, so it gets its Condition.
+func (expander *serverlessExpander) addResource(name string, resource map[string]interface{}, source map[string]interface{}) error {
+	if _, exists := expander.resources[name]; exists {
+		return errors.New("Resource " + name + " generated by " + ServerlessTransform + " transform already exists in the template")
+	}
+	if condition, hasCondition := source["Condition"]; hasCondition {
+		resource["Condition"] = condition
+	}
+	expander.resources[name] = resource
+	return nil
+}
+
+func newResource(resourceType string, properties map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"Type": resourceType, "Properties": properties}
+}
+
+// Properties of AWS::Serverless::Function which are the same as properties of AWS::Lambda::Function.
+var functionProperties = []string{
+	"Architectures",
+	"CodeSigningConfigArn",
+	"Description",
+	"Environment",
+	"EphemeralStorage",
+	"FileSystemConfigs",
+	"FunctionName",
+	"Handler",
+	"ImageConfig",
+	"KmsKeyArn",
+	"Layers",
+	"MemorySize",
+	"PackageType",
+	"ReservedConcurrentExecutions",
+	"Runtime",
+	"Timeout",
+	"VpcConfig",
+}
+
+func (expander *serverlessExpander) expandFunction(name string, resource map[string]interface{}) error {
+	properties := mergeGlobals(getProperties(resource), expander.globals["Function"])
+	function := make(map[string]interface{})
+	copyProperties(properties, function, functionProperties...)
+
+	if inlineCode, ok := properties["InlineCode"]; ok {
+		function["Code"] = map[string]interface{}{"ZipFile": inlineCode}
+	} else if imageURI, ok := properties["ImageUri"]; ok {
+		function["Code"] = map[string]interface{}{"ImageUri": imageURI}
+		if _, hasPackageType := function["PackageType"]; !hasPackageType {
+			function["PackageType"] = "Image"
+		}
+	} else if codeURI, ok := properties["CodeUri"]; ok {
+		code, err := s3Location(codeURI, "S3Bucket", "S3Key", "S3ObjectVersion")
+		if err != nil {
+			return err
+		}
+		function["Code"] = code
+	}
+	if tracing, ok := properties["Tracing"]; ok {
+		function["TracingConfig"] = map[string]interface{}{"Mode": tracing}
+	}
+	if deadLetterQueue, ok := properties["DeadLetterQueue"].(map[string]interface{}); ok {
+		function["DeadLetterConfig"] = map[string]interface{}{"TargetArn": deadLetterQueue["TargetArn"]}
+	}
+	function["Tags"] = tagList(properties["Tags"], map[string]interface{}{"lambda:createdBy": "SAM"})
+
+	resource["Type"] = "AWS::Lambda::Function"
+	resource["Properties"] = function
+
+	eventPolicies, err := expander.expandEvents(name, resource, properties["Events"])
+	if err != nil {
+		return err
+	}
+	if role, hasRole := properties["Role"]; hasRole {
+		function["Role"] = role
+	} else {
+		roleName := name + "Role"
+		if err := expander.addResource(roleName, expander.createFunctionRole(name, properties, eventPolicies), resource); err != nil {
+			return err
+		}
+		function["Role"] = getAtt(roleName, "Arn")
+	}
+	if alias, hasAlias := properties["AutoPublishAlias"]; hasAlias {
+		return expander.addFunctionAlias(name, alias, resource)
+	}
+	return nil
+}
+
+func managedPolicyArn(policyName string) interface{} {
+	return sub("arn:${AWS::Partition}:iam::aws:policy/"+policyName, nil)
+}
+
+// createFunctionRole creates an execution role of the function with policies from its Policies property
+// and policies required by its events.
+func (expander *serverlessExpander) createFunctionRole(name string, properties map[string]interface{}, eventPolicies []string) map[string]interface{} {
+	managedPolicies := []interface{}{managedPolicyArn("service-role/AWSLambdaBasicExecutionRole")}
+	if tracing, ok := properties["Tracing"]; ok && tracing == "Active" {
+		managedPolicies = append(managedPolicies, managedPolicyArn("AWSXrayWriteOnlyAccess"))
+	}
+	if _, hasVpc := properties["VpcConfig"]; hasVpc {
+		managedPolicies = append(managedPolicies, managedPolicyArn("service-role/AWSLambdaVPCAccessExecutionRole"))
+	}
+	for _, policy := range eventPolicies {
+		managedPolicies = append(managedPolicies, managedPolicyArn(policy))
+	}
+
+	var inlinePolicies []interface{}
+	policies := properties["Policies"]
+	if _, isList := policies.([]interface{}); !isList && policies != nil {
+		policies = []interface{}{policies}
+	}
+	policyList, _ := policies.([]interface{})
+	for index, policy := range policyList {
+		switch value := policy.(type) {
+		case string:
+			if strings.HasPrefix(value, "arn:") {
+				managedPolicies = append(managedPolicies, value)
+			} else {
+				managedPolicies = append(managedPolicies, managedPolicyArn(value))
+			}
+		case map[string]interface{}:
+			if isIntrinsicFunction(value) {
+				managedPolicies = append(managedPolicies, value)
+			} else if _, isDocument := value["Statement"]; isDocument {
+				inlinePolicies = append(inlinePolicies, map[string]interface{}{
+					"PolicyName":     fmt.Sprintf("%sRolePolicy%d", name, index),
+					"PolicyDocument": value,
+				})
+			} else {
+				expander.logger.Warning("Policy template " + strings.Join(sortedKeys(value), ", ") + " of function " + name + " is not supported by the local expansion of " + ServerlessTransform + " transform")
+			}
+		}
+	}
+
+	role := map[string]interface{}{
+		"AssumeRolePolicyDocument": map[string]interface{}{
+			"Version": "2012-10-17",
+			"Statement": []interface{}{map[string]interface{}{
+				"Effect":    "Allow",
+				"Action":    []interface{}{"sts:AssumeRole"},
+				"Principal": map[string]interface{}{"Service": []interface{}{"lambda.amazonaws.com"}},
+			}},
+		},
+		"ManagedPolicyArns": managedPolicies,
+		"Tags":              tagList(nil, map[string]interface{}{"lambda:createdBy": "SAM"}),
+	}
+	if len(inlinePolicies) > 0 {
+		role["Policies"] = inlinePolicies
+	}
+	copyProperties(properties, role, "PermissionsBoundary")
+	return newResource("AWS::IAM::Role", role)
+}
+
+// addFunctionAlias adds a version of the function and an alias which points at it, like AutoPublishAlias does.
+func (expander *serverlessExpander) addFunctionAlias(name string, alias interface{}, function map[string]interface{}) error {
+	versionName := name + "Version"
+	version := newResource("AWS::Lambda::Version", map[string]interface{}{"FunctionName": ref(name)})
+	version["DeletionPolicy"] = "Retain"
+	if err := expander.addResource(versionName, version, function); err != nil {
+		return err
+	}
+	aliasName := name + "Alias"
+	if aliasString, isString := alias.(string); isString {
+		aliasName += aliasString
+	}
+	return expander.addResource(aliasName, newResource("AWS::Lambda::Alias", map[string]interface{}{
+		"Name":            alias,
+		"FunctionName":    ref(name),
+		"FunctionVersion": getAtt(versionName, "Version"),
+	}), function)
+}
+
+// DynamoDB attribute types of SimpleTable primary key types.
+var simpleTableKeyTypes = map[string]string{
+	"String": "S",
+	"Number": "N",
+	"Binary": "B",
+}
+
+func (expander *serverlessExpander) expandSimpleTable(resource map[string]interface{}) error {
+	properties := mergeGlobals(getProperties(resource), expander.globals["SimpleTable"])
+	keyName, keyType := interface{}("id"), interface{}("S")
+	if primaryKey, hasPrimaryKey := properties["PrimaryKey"].(map[string]interface{}); hasPrimaryKey {
+		keyName = primaryKey["Name"]
+		keyType = primaryKey["Type"]
+		if simpleType, isString := keyType.(string); isString {
+			attributeType, isSupported := simpleTableKeyTypes[simpleType]
+			if !isSupported {
+				return errors.New("Type of PrimaryKey has to be one of String, Number and Binary")
+			}
+			keyType = attributeType
+		}
+	}
+
+	table := map[string]interface{}{
+		"AttributeDefinitions": []interface{}{map[string]interface{}{"AttributeName": keyName, "AttributeType": keyType}},
+		"KeySchema":            []interface{}{map[string]interface{}{"AttributeName": keyName, "KeyType": "HASH"}},
+	}
+	if throughput, hasThroughput := properties["ProvisionedThroughput"]; hasThroughput {
+		table["ProvisionedThroughput"] = throughput
+	} else {
+		table["BillingMode"] = "PAY_PER_REQUEST"
+	}
+	copyProperties(properties, table, "TableName", "SSESpecification")
+	if tags, hasTags := properties["Tags"]; hasTags {
+		table["Tags"] = tagList(tags, nil)
+	}
+
+	resource["Type"] = "AWS::DynamoDB::Table"
+	resource["Properties"] = table
+	return nil
+}
+
+func (expander *serverlessExpander) expandLayerVersion(resource map[string]interface{}) error {
+	properties := getProperties(resource)
+	layer := make(map[string]interface{})
+	if contentURI, ok := properties["ContentUri"]; ok {
+		content, err := s3Location(contentURI, "S3Bucket", "S3Key", "S3ObjectVersion")
+		if err != nil {
+			return err
+		}
+		layer["Content"] = content
+	}
+	copyProperties(properties, layer, "CompatibleArchitectures", "CompatibleRuntimes", "Description", "LayerName", "LicenseInfo")
+
+	resource["Type"] = "AWS::Lambda::LayerVersion"
+	resource["Properties"] = layer
+	resource["DeletionPolicy"] = "Retain"
+	if retentionPolicy, ok := properties["RetentionPolicy"]; ok {
+		resource["DeletionPolicy"] = retentionPolicy
+	}
+	return nil
+}
+
+// expandApplication translates the application into a nested stack. Applications from AWS Serverless Application
+// Repository are not supported, because their templates are not available locally.
+func (expander *serverlessExpander) expandApplication(name string, resource map[string]interface{}) error {
+	properties := getProperties(resource)
+	location, isString := properties["Location"].(string)
+	if !isString {
+		expander.logger.Warning("Application " + name + " from AWS Serverless Application Repository is not supported by the local expansion of " + ServerlessTransform + " transform")
+		return nil
+	}
+	stack := map[string]interface{}{"TemplateURL": location}
+	copyProperties(properties, stack, "NotificationARNs", "Parameters", "TimeoutInMinutes")
+	if tags, hasTags := properties["Tags"]; hasTags {
+		stack["Tags"] = tagList(tags, nil)
+	}
+
+	resource["Type"] = "AWS::CloudFormation::Stack"
+	resource["Properties"] = stack
+	return nil
+}
@@ -0,0 +1,274 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func expandTemplate(t *testing.T, body string) (map[string]interface{}, map[string]interface{}, string, error) {
+	var tmpl map[string]interface{}
+	assert.Nil(t, yaml.Unmarshal([]byte(body), &tmpl))
+	output := &bytes.Buffer{}
+	err := ExpandServerless(tmpl, &logger.Logger{Output: output})
+	resources, _ := tmpl["Resources"].(map[string]interface{})
+	return tmpl, resources, output.String(), err
+}
+
+func getResource(resources map[string]interface{}, name string) (string, map[string]interface{}) {
+	resource, _ := resources[name].(map[string]interface{})
+	resourceType, _ := resource["Type"].(string)
+	properties, _ := resource["Properties"].(map[string]interface{})
+	return resourceType, properties
+}
+
+func TestExpandFunction(t *testing.T) {
+	tmpl, resources, _, err := expandTemplate(t, `
+Transform: AWS::Serverless-2016-10-31
+Globals:
+  Function:
+    Runtime: python3.9
+    Timeout: 10
+    Environment:
+      Variables:
+        STAGE: prod
+Resources:
+  Hello:
+    Type: AWS::Serverless::Function
+    Properties:
+      Handler: app.handler
+      CodeUri: hello/
+      Timeout: 30
+      Environment:
+        Variables:
+          TABLE: table
+      Policies:
+        - AmazonS3ReadOnlyAccess
+        - Statement:
+            - Effect: Allow
+              Action: sqs:SendMessage
+              Resource: "*"
+      Events:
+        Get:
+          Type: Api
+          Properties:
+            Path: /hello/{name}
+            Method: get
+        Nightly:
+          Type: Schedule
+          Properties:
+            Schedule: rate(1 day)
+        Queue:
+          Type: SQS
+          Properties:
+            Queue: {"Fn::GetAtt": [Queue, Arn]}
+            BatchSize: 5
+`)
+	assert.Nil(t, err)
+	assert.NotContains(t, tmpl, "Transform")
+	assert.NotContains(t, tmpl, "Globals")
+
+	resourceType, function := getResource(resources, "Hello")
+	assert.Equal(t, "AWS::Lambda::Function", resourceType)
+	assert.Equal(t, "python3.9", function["Runtime"])
+	assert.Equal(t, float64(30), function["Timeout"])
+	assert.Equal(t, map[string]interface{}{"Variables": map[string]interface{}{"STAGE": "prod", "TABLE": "table"}}, function["Environment"])
+	assert.Equal(t, map[string]interface{}{"S3Bucket": localPackageBucket, "S3Key": "hello/"}, function["Code"])
+	assert.Equal(t, getAtt("HelloRole", "Arn"), function["Role"])
+
+	resourceType, role := getResource(resources, "HelloRole")
+	assert.Equal(t, "AWS::IAM::Role", resourceType)
+	assert.Equal(t, []interface{}{
+		managedPolicyArn("service-role/AWSLambdaBasicExecutionRole"),
+		managedPolicyArn("service-role/AWSLambdaSQSQueueExecutionRole"),
+		managedPolicyArn("AmazonS3ReadOnlyAccess"),
+	}, role["ManagedPolicyArns"])
+	assert.Len(t, role["Policies"], 1)
+
+	for name, expectedType := range map[string]string{
+		"ServerlessRestApi":           "AWS::ApiGateway::RestApi",
+		"ServerlessRestApiDeployment": "AWS::ApiGateway::Deployment",
+		"ServerlessRestApiProdStage":  "AWS::ApiGateway::Stage",
+		"HelloGetPermission":          "AWS::Lambda::Permission",
+		"HelloNightly":                "AWS::Events::Rule",
+		"HelloNightlyPermission":      "AWS::Lambda::Permission",
+		"HelloQueue":                  "AWS::Lambda::EventSourceMapping",
+	} {
+		resourceType, _ := getResource(resources, name)
+		assert.Equal(t, expectedType, resourceType, name)
+	}
+
+	_, restAPI := getResource(resources, "ServerlessRestApi")
+	paths := restAPI["Body"].(map[string]interface{})["paths"].(map[string]interface{})
+	assert.Contains(t, paths["/hello/{name}"], "get")
+	_, permission := getResource(resources, "HelloGetPermission")
+	assert.Equal(t, sub("arn:${AWS::Partition}:execute-api:${AWS::Region}:${AWS::AccountId}:${__ApiId__}/*/GET/hello/*",
+		map[string]interface{}{"__ApiId__": ref("ServerlessRestApi")}), permission["SourceArn"])
+	_, mapping := getResource(resources, "HelloQueue")
+	assert.Equal(t, getAtt("Queue", "Arn"), mapping["EventSourceArn"])
+	assert.Equal(t, float64(5), mapping["BatchSize"])
+}
+
+func TestExpandAPIsTablesAndLayers(t *testing.T) {
+	_, resources, _, err := expandTemplate(t, `
+Transform: [AWS::Serverless-2016-10-31]
+Resources:
+  Api:
+    Type: AWS::Serverless::HttpApi
+  Function:
+    Type: AWS::Serverless::Function
+    Properties:
+      Handler: index.handler
+      Runtime: nodejs18.x
+      InlineCode: exports.handler = async () => {}
+      Role: arn:aws:iam::123456789012:role/lambda
+      Events:
+        Root:
+          Type: HttpApi
+          Properties:
+            ApiId: {"Ref": Api}
+  Table:
+    Type: AWS::Serverless::SimpleTable
+    Properties:
+      PrimaryKey:
+        Name: key
+        Type: Number
+  Layer:
+    Type: AWS::Serverless::LayerVersion
+    Properties:
+      ContentUri: s3://bucket/layer.zip
+      RetentionPolicy: Delete
+`)
+	assert.Nil(t, err)
+	assert.NotContains(t, resources, "FunctionRole")
+	assert.NotContains(t, resources, "ServerlessHttpApi")
+
+	resourceType, api := getResource(resources, "Api")
+	assert.Equal(t, "AWS::ApiGatewayV2::Api", resourceType)
+	paths := api["Body"].(map[string]interface{})["paths"].(map[string]interface{})
+	assert.Contains(t, paths["$default"], "x-amazon-apigateway-any-method")
+	resourceType, stage := getResource(resources, "ApiApiGatewayDefaultStage")
+	assert.Equal(t, "AWS::ApiGatewayV2::Stage", resourceType)
+	assert.Equal(t, "$default", stage["StageName"])
+
+	_, function := getResource(resources, "Function")
+	assert.Equal(t, map[string]interface{}{"ZipFile": "exports.handler = async () => {}"}, function["Code"])
+
+	resourceType, table := getResource(resources, "Table")
+	assert.Equal(t, "AWS::DynamoDB::Table", resourceType)
+	assert.Equal(t, []interface{}{map[string]interface{}{"AttributeName": "key", "AttributeType": "N"}}, table["AttributeDefinitions"])
+	assert.Equal(t, "PAY_PER_REQUEST", table["BillingMode"])
+
+	resourceType, layer := getResource(resources, "Layer")
+	assert.Equal(t, "AWS::Lambda::LayerVersion", resourceType)
+	assert.Equal(t, map[string]interface{}{"S3Bucket": "bucket", "S3Key": "layer.zip"}, layer["Content"])
+	assert.Equal(t, "Delete", resources["Layer"].(map[string]interface{})["DeletionPolicy"])
+}
+
+func TestExpandS3Event(t *testing.T) {
+	_, resources, _, err := expandTemplate(t, `
+Transform: AWS::Serverless-2016-10-31
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+  Function:
+    Type: AWS::Serverless::Function
+    Properties:
+      Handler: index.handler
+      Runtime: nodejs18.x
+      CodeUri: s3://code/function.zip
+      Events:
+        Upload:
+          Type: S3
+          Properties:
+            Bucket: {"Ref": Bucket}
+            Events: s3:ObjectCreated:*
+`)
+	assert.Nil(t, err)
+	_, bucket := getResource(resources, "Bucket")
+	configurations := bucket["NotificationConfiguration"].(map[string]interface{})["LambdaConfigurations"]
+	assert.Equal(t, []interface{}{map[string]interface{}{"Event": "s3:ObjectCreated:*", "Function": getAtt("Function", "Arn")}}, configurations)
+	assert.Equal(t, "FunctionUploadPermission", resources["Bucket"].(map[string]interface{})["DependsOn"])
+
+	_, _, _, err = expandTemplate(t, `
+Transform: AWS::Serverless-2016-10-31
+Resources:
+  Function:
+    Type: AWS::Serverless::Function
+    Properties:
+      CodeUri: s3://code/function.zip
+      Events:
+        Upload:
+          Type: S3
+          Properties:
+            Bucket: existing-bucket
+            Events: s3:ObjectCreated:*
+`)
+	assert.EqualError(t, err, "Could not expand function Function: Event Upload: Bucket has to be a Ref to AWS::S3::Bucket declared in the template")
+}
+
+func TestExpandUnsupportedResource(t *testing.T) {
+	_, resources, output, err := expandTemplate(t, `
+Transform: AWS::Serverless-2016-10-31
+Resources:
+  StateMachine:
+    Type: AWS::Serverless::StateMachine
+`)
+	assert.Nil(t, err)
+	resourceType, _ := getResource(resources, "StateMachine")
+	assert.Equal(t, "AWS::Serverless::StateMachine", resourceType)
+	assert.Contains(t, output, "WARNING: Resource StateMachine of type AWS::Serverless::StateMachine is not supported")
+}
+
+func TestGeneratedResourceConflict(t *testing.T) {
+	_, _, _, err := expandTemplate(t, `
+Transform: AWS::Serverless-2016-10-31
+Resources:
+  Function:
+    Type: AWS::Serverless::Function
+    Properties:
+      CodeUri: s3://code/function.zip
+  FunctionRole:
+    Type: AWS::IAM::Role
+`)
+	assert.NotNil(t, err)
+}
+
+func TestRemoveTransform(t *testing.T) {
+	tmpl := map[string]interface{}{"Transform": []interface{}{"AWS::Include", ServerlessTransform}}
+	assert.True(t, HasTransform(tmpl, ServerlessTransform))
+	removeTransform(tmpl, ServerlessTransform)
+	assert.False(t, HasTransform(tmpl, ServerlessTransform))
+	assert.Equal(t, []interface{}{"AWS::Include"}, tmpl["Transform"])
+}
+
+func TestMergeGlobals(t *testing.T) {
+	merged := mergeGlobals(map[string]interface{}{
+		"Timeout": 5,
+		"Layers":  []interface{}{"second"},
+	}, map[string]interface{}{
+		"Timeout":    3,
+		"MemorySize": 256,
+		"Layers":     []interface{}{"first"},
+	})
+	assert.Equal(t, map[string]interface{}{"Timeout": 5, "MemorySize": 256, "Layers": []interface{}{"first", "second"}}, merged)
+}
@@ -0,0 +1,210 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transform provides expansion of CloudFormation transforms (macros), so templates which use them
+// can be validated locally.
+package transform
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// HasTransform checks if the transform is declared in the Transform section of the template.
+func HasTransform(tmpl map[string]interface{}, name string) bool {
+	switch transform := tmpl["Transform"].(type) {
+	case string:
+		return transform == name
+	case []interface{}:
+		for _, element := range transform {
+			if element == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// removeTransform removes the expanded transform from the Transform section of the template.
+func removeTransform(tmpl map[string]interface{}, name string) {
+	switch transform := tmpl["Transform"].(type) {
+	case string:
+		if transform == name {
+			delete(tmpl, "Transform")
+		}
+	case []interface{}:
+		remaining := make([]interface{}, 0, len(transform))
+		for _, element := range transform {
+			if element != name {
+				remaining = append(remaining, element)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(tmpl, "Transform")
+		} else {
+			tmpl["Transform"] = remaining
+		}
+	}
+}
+
+func isIntrinsicFunction(value interface{}) bool {
+	function, isMap := value.(map[string]interface{})
+	if !isMap || len(function) != 1 {
+		return false
+	}
+	for key := range function {
+		return key == "Ref" || key == "Condition" || strings.HasPrefix(key, "Fn::")
+	}
+	return false
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"Ref": name}
+}
+
+func getAtt(name string, attribute string) map[string]interface{} {
+	return map[string]interface{}{"Fn::GetAtt": []interface{}{name, attribute}}
+}
+
+func sub(text string, variables map[string]interface{}) map[string]interface{} {
+	if len(variables) == 0 {
+		return map[string]interface{}{"Fn::Sub": text}
+	}
+	return map[string]interface{}{"Fn::Sub": []interface{}{text, variables}}
+}
+
+// getRefTarget returns the logical ID of the resource, which is given by its name or by Ref.
+func getRefTarget(value interface{}) (string, bool) {
+	switch target := value.(type) {
+	case string:
+		return target, true
+	case map[string]interface{}:
+		name, isString := target["Ref"].(string)
+		return name, isString && len(target) == 1
+	}
+	return "", false
+}
+
+func getProperties(resource map[string]interface{}) map[string]interface{} {
+	if properties, ok := resource["Properties"].(map[string]interface{}); ok {
+		return properties
+	}
+	return map[string]interface{}{}
+}
+
+func copyProperties(from map[string]interface{}, to map[string]interface{}, names ...string) {
+	for _, name := range names {
+		if value, ok := from[name]; ok {
+			to[name] = value
+		}
+	}
+}
+
+func sortedKeys(elements map[string]interface{}) []string {
+	keys := make([]string, 0, len(elements))
+	for key := range elements {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// addDependsOn adds the dependency to DependsOn attribute of the resource.
+func addDependsOn(resource map[string]interface{}, dependency string) {
+	switch dependsOn := resource["DependsOn"].(type) {
+	case string:
+		resource["DependsOn"] = []interface{}{dependsOn, dependency}
+	case []interface{}:
+		resource["DependsOn"] = append(dependsOn, dependency)
+	default:
+		resource["DependsOn"] = dependency
+	}
+}
+
+// tagList converts tags given as a map to a list of Key and Value pairs, which most resources use.
+func tagList(tags interface{}, extraTags map[string]interface{}) interface{} {
+	tagMap, isMap := tags.(map[string]interface{})
+	if tags != nil && (!isMap || isIntrinsicFunction(tags)) {
+		return tags
+	}
+	list := make([]interface{}, 0, len(tagMap)+len(extraTags))
+	for _, key := range sortedKeys(tagMap) {
+		list = append(list, map[string]interface{}{"Key": key, "Value": tagMap[key]})
+	}
+	for _, key := range sortedKeys(extraTags) {
+		list = append(list, map[string]interface{}{"Key": key, "Value": extraTags[key]})
+	}
+	return list
+}
+
+// mergeGlobals merges properties of the resource with global properties. Properties of the resource take precedence,
+// maps are merged and global lists are prepended to lists of the resource.
+func mergeGlobals(properties map[string]interface{}, globals interface{}) map[string]interface{} {
+	globalProperties, ok := globals.(map[string]interface{})
+	if !ok {
+		return properties
+	}
+	merged := make(map[string]interface{}, len(properties)+len(globalProperties))
+	for name, value := range globalProperties {
+		merged[name] = value
+	}
+	for name, value := range properties {
+		switch element := value.(type) {
+		case map[string]interface{}:
+			if globalMap, isMap := merged[name].(map[string]interface{}); isMap && !isIntrinsicFunction(element) && !isIntrinsicFunction(globalMap) {
+				merged[name] = mergeGlobals(element, globalMap)
+				continue
+			}
+		case []interface{}:
+			if globalList, isList := merged[name].([]interface{}); isList {
+				merged[name] = append(append([]interface{}{}, globalList...), element...)
+				continue
+			}
+		}
+		merged[name] = value
+	}
+	return merged
+}
+
+// Bucket of code and layers stored in local paths, which are uploaded to S3 by sam package. They are validated
+// as if they were uploaded.
+const localPackageBucket = "local-package"
+
+// s3Location converts location of code in SAM format (s3://bucket/key, a map with Bucket, Key and Version,
+// or a local path) to S3 location with the given property names.
+func s3Location(location interface{}, bucketProperty string, keyProperty string, versionProperty string) (map[string]interface{}, error) {
+	switch value := location.(type) {
+	case string:
+		if strings.HasPrefix(value, "s3://") {
+			parts := strings.SplitN(strings.TrimPrefix(value, "s3://"), "/", 2)
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return nil, errors.New("Invalid S3 location " + value + ", it should be s3://<bucket>/<key>")
+			}
+			return map[string]interface{}{bucketProperty: parts[0], keyProperty: parts[1]}, nil
+		}
+		return map[string]interface{}{bucketProperty: localPackageBucket, keyProperty: value}, nil
+	case map[string]interface{}:
+		result := make(map[string]interface{})
+		for from, to := range map[string]string{"Bucket": bucketProperty, "Key": keyProperty, "Version": versionProperty} {
+			if element, ok := value[from]; ok {
+				result[to] = element
+			}
+		}
+		return result, nil
+	}
+	return nil, errors.New("Location has to be a string or a map with Bucket and Key")
+}
@@ -0,0 +1,194 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Appliscale/perun/helpers"
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+)
+
+var deletionPolicies = []string{"Delete", "Retain", "RetainExceptOnCreate", "Snapshot"}
+var updateReplacePolicies = []string{"Delete", "Retain", "Snapshot"}
+
+// Resource types which can be snapshotted before deletion or replacement.
+var snapshotResourceTypes = []string{
+	"AWS::DocDB::DBCluster",
+	"AWS::EC2::Volume",
+	"AWS::ElastiCache::CacheCluster",
+	"AWS::ElastiCache::ReplicationGroup",
+	"AWS::Neptune::DBCluster",
+	"AWS::RDS::DBCluster",
+	"AWS::RDS::DBInstance",
+	"AWS::Redshift::Cluster",
+}
+
+// policyStructure maps policy elements to their attributes. Elements without attributes take a single value.
+type policyStructure map[string][]string
+
+// CreationPolicy elements supported by resource types.
+var creationPolicies = map[string]policyStructure{
+	"AWS::AutoScaling::AutoScalingGroup": {
+		"AutoScalingCreationPolicy": {"MinSuccessfulInstancesPercent"},
+		"ResourceSignal":            {"Count", "Timeout"},
+	},
+	"AWS::EC2::Instance":                 {"ResourceSignal": {"Count", "Timeout"}},
+	"AWS::CloudFormation::WaitCondition": {"ResourceSignal": {"Count", "Timeout"}},
+	"AWS::AppStream::Fleet":              {"StartFleet": nil},
+}
+
+// UpdatePolicy elements supported by resource types.
+var updatePolicies = map[string]policyStructure{
+	"AWS::AutoScaling::AutoScalingGroup": {
+		"AutoScalingReplacingUpdate": {"WillReplace"},
+		"AutoScalingRollingUpdate": {"MaxBatchSize", "MinActiveInstancesPercent", "MinInstancesInService",
+			"MinSuccessfulInstancesPercent", "PauseTime", "SuspendProcesses", "WaitOnResourceSignals"},
+		"AutoScalingScheduledAction": {"IgnoreUnmodifiedGroupSizeProperties"},
+	},
+	"AWS::ElastiCache::ReplicationGroup": {"UseOnlineResharding": nil},
+	"AWS::Elasticsearch::Domain":         {"EnableVersionUpgrade": nil},
+	"AWS::OpenSearchService::Domain":     {"EnableVersionUpgrade": nil},
+	"AWS::Lambda::Alias": {
+		"CodeDeployLambdaAliasUpdate": {"AfterAllowTrafficHook", "ApplicationName", "BeforeAllowTrafficHook", "DeploymentGroupName"},
+	},
+}
+
+// Policy attributes which take ISO 8601 duration.
+var durationAttributes = []string{"Timeout", "PauseTime"}
+
+var durationRegex = regexp.MustCompile(`^PT(\d+H)?(\d+M)?(\d+S)?$`)
+
+// validateResourceAttributes checks DeletionPolicy, UpdateReplacePolicy, DependsOn, CreationPolicy and UpdatePolicy of every resource.
+func validateResourceAttributes(tmpl template.Template, sink logger.LoggerInt) bool {
+	valid := true
+	for _, resourceName := range sortedResourceNames(tmpl.Resources) {
+		resource := tmpl.Resources[resourceName]
+		errors := checkRemovalPolicy("DeletionPolicy", resource.DeletionPolicy, deletionPolicies, resource.Type)
+		errors = append(errors, checkRemovalPolicy("UpdateReplacePolicy", resource.UpdateReplacePolicy, updateReplacePolicies, resource.Type)...)
+		errors = append(errors, checkDependsOn(resourceName, resource, tmpl.Resources)...)
+		errors = append(errors, checkPolicyStructure("CreationPolicy", resource.CreationPolicy, creationPolicies, resource.Type)...)
+		errors = append(errors, checkPolicyStructure("UpdatePolicy", resource.UpdatePolicy, updatePolicies, resource.Type)...)
+		for _, e := range errors {
+			sink.AddResourceForValidation(resourceName).AddError(e.path, e.String())
+			valid = false
+		}
+	}
+	return valid
+}
+
+func checkRemovalPolicy(attribute string, value interface{}, allowed []string, resourceType string) []pathError {
+	if value == nil {
+		return nil
+	}
+	policy, isString := value.(string)
+	if !isString {
+		return []pathError{{attribute, attribute + " has to be a string literal, it cannot be parametrized"}}
+	}
+	if !helpers.SliceContains(allowed, policy) {
+		return []pathError{{attribute, attribute + " has to be one of: " + strings.Join(allowed, ", ") + suggestion(policy, allowed)}}
+	}
+	if policy == "Snapshot" && !helpers.SliceContains(snapshotResourceTypes, resourceType) {
+		return []pathError{{attribute, "Snapshot is not supported by " + resourceType + ", it can be used only with " + strings.Join(snapshotResourceTypes, ", ")}}
+	}
+	return nil
+}
+
+func checkDependsOn(resourceName string, resource template.Resource, resources map[string]template.Resource) (errors []pathError) {
+	switch dependsOn := resource.DependsOn.(type) {
+	case nil, string:
+	case []interface{}:
+		for _, dependency := range dependsOn {
+			if _, isString := dependency.(string); !isString {
+				errors = append(errors, pathError{"DependsOn", "DependsOn has to be a resource name or a list of resource names"})
+				break
+			}
+		}
+	default:
+		errors = append(errors, pathError{"DependsOn", "DependsOn has to be a resource name or a list of resource names"})
+	}
+	for _, dependency := range getDependsOn(resource) {
+		if dependency == resourceName {
+			errors = append(errors, pathError{"DependsOn", "Resource can't depend on itself"})
+		} else if _, isResource := resources[dependency]; !isResource {
+			errors = append(errors, pathError{"DependsOn", "DependsOn to undefined resource " + dependency + suggestion(dependency, sortedResourceNames(resources))})
+		}
+	}
+	return
+}
+
+// checkPolicyStructure checks if the resource type supports the policy and if the policy contains only supported elements and attributes.
+func checkPolicyStructure(attribute string, value interface{}, supportedPolicies map[string]policyStructure, resourceType string) (errors []pathError) {
+	if value == nil {
+		return nil
+	}
+	structure, isSupported := supportedPolicies[resourceType]
+	if !isSupported {
+		supportedTypes := make([]string, 0, len(supportedPolicies))
+		for supportedType := range supportedPolicies {
+			supportedTypes = append(supportedTypes, supportedType)
+		}
+		sort.Strings(supportedTypes)
+		return []pathError{{attribute, attribute + " is not supported by " + resourceType + ", it can be used only with " + strings.Join(supportedTypes, ", ")}}
+	}
+	policy, isMap := value.(map[string]interface{})
+	if !isMap {
+		return []pathError{{attribute, attribute + " has to be an object"}}
+	}
+	if _, isFunction := isIntrinsicFunction(policy); isFunction {
+		return nil
+	}
+
+	elements := make([]string, 0, len(structure))
+	for element := range structure {
+		elements = append(elements, element)
+	}
+	for _, element := range sortedKeys(policy) {
+		path := attribute + "." + element
+		attributes, isElement := structure[element]
+		if !isElement {
+			errors = append(errors, pathError{path, element + " is not supported in " + attribute + " of " + resourceType + suggestion(element, elements)})
+			continue
+		}
+		if attributes == nil {
+			continue
+		}
+		elementValue, isMap := policy[element].(map[string]interface{})
+		if !isMap {
+			errors = append(errors, pathError{path, element + " has to be an object"})
+			continue
+		}
+		if _, isFunction := isIntrinsicFunction(elementValue); isFunction {
+			continue
+		}
+		for _, name := range sortedKeys(elementValue) {
+			if !helpers.SliceContains(attributes, name) {
+				errors = append(errors, pathError{path + "." + name, name + " is not supported in " + element + suggestion(name, attributes)})
+			} else if duration, isString := elementValue[name].(string); isString && helpers.SliceContains(durationAttributes, name) && !isDuration(duration) {
+				errors = append(errors, pathError{path + "." + name, name + " has to be an ISO 8601 duration in format PT#H#M#S, but it is " + duration})
+			}
+		}
+	}
+	return
+}
+
+func isDuration(value string) bool {
+	return value != "PT" && durationRegex.MatchString(value)
+}
@@ -0,0 +1,125 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"testing"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidResourceAttributes(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Resources:
+  Database:
+    Type: AWS::RDS::DBInstance
+    DeletionPolicy: Snapshot
+    UpdateReplacePolicy: Retain
+    DependsOn: [Group]
+  Group:
+    Type: AWS::AutoScaling::AutoScalingGroup
+    CreationPolicy:
+      ResourceSignal:
+        Count: 2
+        Timeout: PT15M
+    UpdatePolicy:
+      AutoScalingRollingUpdate:
+        MaxBatchSize: 1
+        PauseTime: PT1H30M
+`)
+
+	assert.True(t, validateResourceAttributes(tmpl, &sink))
+	assert.False(t, sink.HasValidationErrors())
+}
+
+func TestInvalidRemovalPolicies(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Parameters:
+  Policy:
+    Type: String
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    DeletionPolicy: Snapshot
+    UpdateReplacePolicy: Retian
+  Queue:
+    Type: AWS::SQS::Queue
+    DeletionPolicy: !Ref Policy
+`)
+
+	assert.False(t, validateResourceAttributes(tmpl, &sink))
+	assert.Equal(t, []string{
+		"DeletionPolicy: Snapshot is not supported by AWS::S3::Bucket, it can be used only with AWS::DocDB::DBCluster, AWS::EC2::Volume, " +
+			"AWS::ElastiCache::CacheCluster, AWS::ElastiCache::ReplicationGroup, AWS::Neptune::DBCluster, AWS::RDS::DBCluster, AWS::RDS::DBInstance, AWS::Redshift::Cluster",
+		"UpdateReplacePolicy: UpdateReplacePolicy has to be one of: Delete, Retain, Snapshot. Did you mean Retain?",
+	}, sink.AddResourceForValidation("Bucket").Errors)
+	assert.Equal(t, []string{"DeletionPolicy: DeletionPolicy has to be a string literal, it cannot be parametrized"},
+		sink.AddResourceForValidation("Queue").Errors)
+}
+
+func TestInvalidDependsOn(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    DependsOn: [Bucket, Queu]
+  Queue:
+    Type: AWS::SQS::Queue
+`)
+
+	assert.False(t, validateResourceAttributes(tmpl, &sink))
+	assert.Equal(t, []string{
+		"DependsOn: Resource can't depend on itself",
+		"DependsOn: DependsOn to undefined resource Queu. Did you mean Queue?",
+	}, sink.AddResourceForValidation("Bucket").Errors)
+}
+
+func TestInvalidCreationAndUpdatePolicies(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    CreationPolicy:
+      ResourceSignal:
+        Count: 1
+  Group:
+    Type: AWS::AutoScaling::AutoScalingGroup
+    CreationPolicy:
+      ResourceSignal:
+        Timeout: 15 minutes
+    UpdatePolicy:
+      AutoScalingRollingUpdate:
+        MaxBatchsize: 1
+      UseOnlineResharding: true
+`)
+
+	assert.False(t, validateResourceAttributes(tmpl, &sink))
+	assert.Equal(t, []string{
+		"CreationPolicy: CreationPolicy is not supported by AWS::S3::Bucket, it can be used only with AWS::AppStream::Fleet, " +
+			"AWS::AutoScaling::AutoScalingGroup, AWS::CloudFormation::WaitCondition, AWS::EC2::Instance",
+	}, sink.AddResourceForValidation("Bucket").Errors)
+	assert.Equal(t, []string{
+		"CreationPolicy.ResourceSignal.Timeout: Timeout has to be an ISO 8601 duration in format PT#H#M#S, but it is 15 minutes",
+		"UpdatePolicy.AutoScalingRollingUpdate.MaxBatchsize: MaxBatchsize is not supported in AutoScalingRollingUpdate. Did you mean MaxBatchSize?",
+		"UpdatePolicy.UseOnlineResharding: UseOnlineResharding is not supported in UpdatePolicy of AWS::AutoScaling::AutoScalingGroup",
+	}, sink.AddResourceForValidation("Group").Errors)
+}
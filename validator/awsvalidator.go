@@ -19,7 +19,9 @@
 package validator
 
 import (
+	"github.com/Appliscale/perun/awsapi"
 	"github.com/Appliscale/perun/context"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"strings"
 )
@@ -37,7 +39,7 @@ func isTemplateValid(context *context.Context, template *string) (bool, error) {
 	templateStruct := cloudformation.ValidateTemplateInput{
 		TemplateBody: template,
 	}
-	_, err := context.CloudFormation.ValidateTemplate(&templateStruct)
+	_, err := getValidationAPI(context).ValidateTemplate(&templateStruct)
 	if err != nil {
 		if strings.Contains(err.Error(), "ExpiredToken:") {
 			context.Logger.Error(err.Error())
@@ -47,3 +49,13 @@ func isTemplateValid(context *context.Context, template *string) (bool, error) {
 	}
 	return true, nil
 }
+
+// getValidationAPI returns CloudFormation API used for remote validation. It can be pointed at a custom endpoint
+// with ValidationEndpoint in the configuration.
+func getValidationAPI(context *context.Context) awsapi.CloudFormationAPI {
+	if context.Config.ValidationEndpoint == "" {
+		return context.CloudFormation
+	}
+	context.Logger.Debug("Using custom validation endpoint: " + context.Config.ValidationEndpoint)
+	return awsapi.NewAWSCloudFormation(cloudformation.New(context.CurrentSession, aws.NewConfig().WithEndpoint(context.Config.ValidationEndpoint)))
+}
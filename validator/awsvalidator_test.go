@@ -4,6 +4,7 @@ import (
 	"github.com/Appliscale/perun/stack/stack_mocks"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
@@ -25,3 +26,17 @@ func TestIsTemplateValid(t *testing.T) {
 	isTemplateValid(ctx, &templateBody)
 	awsValidate(ctx, &templateBody)
 }
+
+func TestValidationEndpoint(t *testing.T) {
+	ctx := stack_mocks.SetupContext(t, []string{"cmd", "validate", "templatePath"})
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockAWSPI := stack_mocks.NewMockCloudFormationAPI(mockCtrl)
+	ctx.CloudFormation = mockAWSPI
+
+	assert.Equal(t, mockAWSPI, getValidationAPI(ctx))
+
+	ctx.Config.ValidationEndpoint = "http://localhost:4566"
+	assert.NotEqual(t, mockAWSPI, getValidationAPI(ctx))
+}
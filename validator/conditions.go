@@ -0,0 +1,155 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Appliscale/perun/helpers"
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+)
+
+// Functions which can define a condition.
+var conditionFunctions = []string{"Fn::And", "Fn::Equals", "Fn::If", "Fn::Not", "Fn::Or", "Condition"}
+
+// Functions which can provide values compared inside conditions.
+var conditionValueFunctions = []string{"Ref", "Fn::FindInMap", "Fn::Join", "Fn::Select", "Fn::Split", "Fn::Sub"}
+
+// findConditionReferences looks for names of conditions used in Fn::If. The Condition function is taken into account
+// only if withConditionFunction is set, because outside of the Conditions section "Condition" is usually a property name.
+func findConditionReferences(value interface{}, path string, withConditionFunction bool) (references []reference) {
+	switch element := value.(type) {
+	case map[string]interface{}:
+		if function, isFunction := isIntrinsicFunction(element); isFunction {
+			switch {
+			case function == "Fn::If":
+				arguments, _ := element[function].([]interface{})
+				if len(arguments) > 0 {
+					if name, ok := arguments[0].(string); ok {
+						references = append(references, reference{Function: function, Target: name, Path: path})
+					}
+				}
+				return append(references, findConditionReferences(arguments, path, withConditionFunction)...)
+			case function == "Condition" && withConditionFunction:
+				if name, ok := element[function].(string); ok {
+					return []reference{{Function: function, Target: name, Path: path}}
+				}
+			}
+		}
+		for _, key := range sortedKeys(element) {
+			references = append(references, findConditionReferences(element[key], joinPath(path, key), withConditionFunction)...)
+		}
+	case []interface{}:
+		for index, item := range element {
+			references = append(references, findConditionReferences(item, path+"["+strconv.Itoa(index)+"]", withConditionFunction)...)
+		}
+	}
+	return
+}
+
+// checkConditionFunctions finds functions which can't be used to define a condition.
+func checkConditionFunctions(value interface{}, path string) (errors []pathError) {
+	switch element := value.(type) {
+	case map[string]interface{}:
+		if function, isFunction := isIntrinsicFunction(element); isFunction {
+			if !helpers.SliceContains(conditionFunctions, function) && !helpers.SliceContains(conditionValueFunctions, function) {
+				return []pathError{{path, "Function " + function + " can't be used in Conditions"}}
+			}
+			return checkConditionFunctions(element[function], joinPath(path, function))
+		}
+		for _, key := range sortedKeys(element) {
+			errors = append(errors, checkConditionFunctions(element[key], joinPath(path, key))...)
+		}
+	case []interface{}:
+		for index, item := range element {
+			errors = append(errors, checkConditionFunctions(item, path+"["+strconv.Itoa(index)+"]")...)
+		}
+	}
+	return
+}
+
+// validateConditions checks condition definitions and usage of conditions in Resources, Outputs and Conditions.
+// Every problem is reported against the element which refers to the condition.
+func validateConditions(tmpl template.Template, sink logger.LoggerInt) bool {
+	valid := true
+	conditionNames := sortedKeys(tmpl.Conditions)
+	report := func(elementName string, ref reference) {
+		path := joinPath(ref.Path, ref.Function)
+		sink.AddResourceForValidation(elementName).AddError(path, path+": Condition "+ref.Target+" is not defined"+suggestion(ref.Target, conditionNames))
+		valid = false
+	}
+
+	for _, resourceName := range sortedResourceNames(tmpl.Resources) {
+		resource := tmpl.Resources[resourceName]
+		references := findConditionReferences(resource.Properties, "Properties", false)
+		if resource.Condition != "" {
+			references = append([]reference{{Function: "Condition", Target: resource.Condition}}, references...)
+		}
+		for _, ref := range references {
+			if _, exists := tmpl.Conditions[ref.Target]; !exists {
+				report(resourceName, ref)
+			}
+		}
+	}
+
+	for _, outputName := range sortedKeys(tmpl.Outputs) {
+		output, _ := tmpl.Outputs[outputName].(map[string]interface{})
+		var references []reference
+		if condition, ok := output["Condition"].(string); ok {
+			references = append(references, reference{Function: "Condition", Target: condition, Path: outputName})
+		}
+		references = append(references, findConditionReferences(output["Value"], joinPath(outputName, "Value"), false)...)
+		for _, ref := range references {
+			if _, exists := tmpl.Conditions[ref.Target]; !exists {
+				report("Outputs", ref)
+			}
+		}
+	}
+
+	graph := make(map[string][]string, len(conditionNames))
+	for _, conditionName := range conditionNames {
+		body := tmpl.Conditions[conditionName]
+		if function, isFunction := isIntrinsicFunction(body); !isFunction || !helpers.SliceContains(conditionFunctions, function) {
+			sink.AddResourceForValidation("Conditions").AddError(conditionName,
+				conditionName+": Condition has to be defined with one of functions: "+strings.Join(conditionFunctions, ", "))
+			valid = false
+		}
+		for _, e := range checkConditionFunctions(body, conditionName) {
+			sink.AddResourceForValidation("Conditions").AddError(e.path, e.String())
+			valid = false
+		}
+
+		dependencies := map[string]bool{}
+		for _, ref := range findConditionReferences(body, conditionName, true) {
+			if _, exists := tmpl.Conditions[ref.Target]; !exists {
+				report("Conditions", ref)
+			} else if !dependencies[ref.Target] {
+				dependencies[ref.Target] = true
+				graph[conditionName] = append(graph[conditionName], ref.Target)
+			}
+		}
+		sort.Strings(graph[conditionName])
+	}
+	for _, cycle := range findCycles(graph) {
+		sink.AddResourceForValidation("Conditions").AddValidationError("Circular dependency between conditions: " + strings.Join(cycle, " -> "))
+		valid = false
+	}
+	return valid
+}
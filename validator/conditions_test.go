@@ -0,0 +1,127 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"testing"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidConditions(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Parameters:
+  Environment:
+    Type: String
+Conditions:
+  IsProduction:
+    Fn::Equals: [!Ref Environment, prod]
+  IsNotProduction:
+    Fn::Not: [Condition: IsProduction]
+  CreateBackup:
+    Fn::And:
+      - Condition: IsProduction
+      - Fn::Equals: [!Select [0, !Split [",", "a,b"]], a]
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Condition: CreateBackup
+    Properties:
+      BucketName:
+        Fn::If: [IsProduction, prod-bucket, dev-bucket]
+Outputs:
+  BucketName:
+    Condition: CreateBackup
+    Value: !Ref Bucket
+`)
+
+	assert.True(t, validateConditions(tmpl, &sink))
+	assert.False(t, sink.HasValidationErrors())
+}
+
+func TestUndefinedConditions(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Conditions:
+  IsProduction:
+    Fn::Equals: [prod, prod]
+  CreateBackup:
+    Fn::Not: [Condition: IsProdction]
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Condition: IsProduciton
+    Properties:
+      BucketName:
+        Fn::If: [Missing, prod-bucket, dev-bucket]
+Outputs:
+  BucketName:
+    Condition: Missing
+    Value: bucket
+`)
+
+	assert.False(t, validateConditions(tmpl, &sink))
+	assert.Equal(t, []string{
+		"Condition: Condition IsProduciton is not defined. Did you mean IsProduction?",
+		"Properties.BucketName.Fn::If: Condition Missing is not defined",
+	}, sink.AddResourceForValidation("Bucket").Errors)
+	assert.Equal(t, []string{"BucketName.Condition: Condition Missing is not defined"},
+		sink.AddResourceForValidation("Outputs").Errors)
+	assert.Equal(t, []string{"CreateBackup.Fn::Not[0].Condition: Condition IsProdction is not defined. Did you mean IsProduction?"},
+		sink.AddResourceForValidation("Conditions").Errors)
+}
+
+func TestNotAllowedFunctionsInConditions(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+Conditions:
+  HasArn:
+    Fn::Equals: [!GetAtt Bucket.Arn, ""]
+  IsTrue:
+    Ref: Enabled
+`)
+
+	assert.False(t, validateConditions(tmpl, &sink))
+	assert.Equal(t, []string{
+		"HasArn.Fn::Equals[0]: Function Fn::GetAtt can't be used in Conditions",
+		"IsTrue: Condition has to be defined with one of functions: Fn::And, Fn::Equals, Fn::If, Fn::Not, Fn::Or, Condition",
+	}, sink.AddResourceForValidation("Conditions").Errors)
+}
+
+func TestCircularConditions(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Conditions:
+  First:
+    Fn::Not: [Condition: Second]
+  Second:
+    Fn::Or:
+      - Condition: Third
+      - Fn::Equals: [a, b]
+  Third:
+    Fn::Not: [Condition: First]
+`)
+
+	assert.False(t, validateConditions(tmpl, &sink))
+	assert.Equal(t, []string{"Circular dependency between conditions: First -> Second -> Third -> First"},
+		sink.AddResourceForValidation("Conditions").Errors)
+}
@@ -0,0 +1,142 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+)
+
+// getDependsOn returns names of resources listed in DependsOn, which can be a single name or a list of names.
+func getDependsOn(resource template.Resource) (dependencies []string) {
+	switch dependsOn := resource.DependsOn.(type) {
+	case string:
+		dependencies = append(dependencies, dependsOn)
+	case []interface{}:
+		for _, dependency := range dependsOn {
+			if name, ok := dependency.(string); ok {
+				dependencies = append(dependencies, name)
+			}
+		}
+	case []string:
+		dependencies = append(dependencies, dependsOn...)
+	}
+	return
+}
+
+// buildDependencyGraph maps every resource to the sorted list of resources it depends on. Dependencies come from
+// DependsOn and from Ref, Fn::GetAtt and Fn::Sub used in resource properties.
+func buildDependencyGraph(resources map[string]template.Resource) map[string][]string {
+	graph := make(map[string][]string, len(resources))
+	for resourceName, resource := range resources {
+		dependencies := make(map[string]bool)
+		for _, dependency := range getDependsOn(resource) {
+			dependencies[dependency] = true
+		}
+		for _, ref := range findReferences(resource.Properties, "") {
+			dependencies[ref.Target] = true
+		}
+
+		graph[resourceName] = []string{}
+		for dependency := range dependencies {
+			if _, isResource := resources[dependency]; isResource {
+				graph[resourceName] = append(graph[resourceName], dependency)
+			}
+		}
+		sort.Strings(graph[resourceName])
+	}
+	return graph
+}
+
+// findCycles walks the dependency graph in depth and reports a cycle for each back edge found, so not every
+// cycle of the graph is reported, but every graph with a cycle has at least one. Duplicated cycles are skipped.
+// Each cycle starts and ends with the same resource, e.g. [A B C A].
+func findCycles(graph map[string][]string) (cycles [][]string) {
+	const (
+		notVisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int, len(graph))
+	found := make(map[string]bool)
+	var path []string
+
+	var visit func(node string)
+	visit = func(node string) {
+		state[node] = inProgress
+		path = append(path, node)
+		for _, next := range graph[node] {
+			switch state[next] {
+			case notVisited:
+				visit(next)
+			case inProgress:
+				cycle := cycleFromPath(path, next)
+				key := strings.Join(cycle, "->")
+				if !found[key] {
+					found[key] = true
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[node] = done
+	}
+
+	nodes := make([]string, 0, len(graph))
+	for node := range graph {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		if state[node] == notVisited {
+			visit(node)
+		}
+	}
+	return
+}
+
+// cycleFromPath cuts the cycle closed by start out of the current path and rotates it, so it begins
+// with the alphabetically first resource. This way every cycle is reported the same way.
+func cycleFromPath(path []string, start string) []string {
+	var cycle []string
+	for index := range path {
+		if path[index] == start {
+			cycle = append(cycle, path[index:]...)
+			break
+		}
+	}
+	first := 0
+	for index := range cycle {
+		if cycle[index] < cycle[first] {
+			first = index
+		}
+	}
+	rotated := append(append([]string{}, cycle[first:]...), cycle[:first]...)
+	return append(rotated, rotated[0])
+}
+
+// validateDependencies reports circular dependencies between resources, which CloudFormation would reject.
+func validateDependencies(tmpl template.Template, sink logger.LoggerInt) bool {
+	cycles := findCycles(buildDependencyGraph(tmpl.Resources))
+	for _, cycle := range cycles {
+		sink.AddResourceForValidation(cycle[0]).AddValidationError("Circular dependency between resources: " + strings.Join(cycle, " -> "))
+	}
+	return len(cycles) == 0
+}
@@ -0,0 +1,98 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"testing"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDependencyGraph(t *testing.T) {
+	resources := map[string]template.Resource{
+		"Vpc": {Type: "AWS::EC2::VPC"},
+		"Subnet": {
+			Type:       "AWS::EC2::Subnet",
+			Properties: map[string]interface{}{"VpcId": map[string]interface{}{"Ref": "Vpc"}},
+			DependsOn:  []interface{}{"Gateway", "Missing"},
+		},
+		"Gateway": {Type: "AWS::EC2::InternetGateway", DependsOn: "Vpc"},
+	}
+
+	graph := buildDependencyGraph(resources)
+
+	assert.Equal(t, []string{"Gateway", "Vpc"}, graph["Subnet"])
+	assert.Equal(t, []string{"Vpc"}, graph["Gateway"])
+	assert.Empty(t, graph["Vpc"])
+}
+
+func TestNoCircularDependencies(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Resources:
+  Vpc:
+    Type: AWS::EC2::VPC
+  Subnet:
+    Type: AWS::EC2::Subnet
+    DependsOn: Vpc
+    Properties:
+      VpcId: !Ref Vpc
+`)
+
+	assert.True(t, validateDependencies(tmpl, &sink))
+}
+
+func TestCircularDependency(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Resources:
+  Queue:
+    Type: AWS::SQS::Queue
+    DependsOn: Topic
+  Topic:
+    Type: AWS::SNS::Topic
+    Properties:
+      DisplayName: !GetAtt Policy.Arn
+  Policy:
+    Type: AWS::SQS::QueuePolicy
+    Properties:
+      Queues:
+        - !Sub "${Queue}"
+`)
+
+	assert.False(t, validateDependencies(tmpl, &sink))
+	assert.Equal(t, []string{"Circular dependency between resources: Policy -> Queue -> Topic -> Policy"},
+		sink.AddResourceForValidation("Policy").Errors)
+}
+
+func TestSelfReference(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := template.Template{
+		Resources: map[string]template.Resource{
+			"Bucket": {
+				Type:       "AWS::S3::Bucket",
+				Properties: map[string]interface{}{"BucketName": map[string]interface{}{"Ref": "Bucket"}},
+			},
+		},
+	}
+
+	assert.False(t, validateDependencies(tmpl, &sink))
+	assert.Equal(t, []string{"Circular dependency between resources: Bucket -> Bucket"},
+		sink.AddResourceForValidation("Bucket").Errors)
+}
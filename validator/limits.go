@@ -0,0 +1,112 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/Appliscale/perun/configuration"
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+)
+
+var logicalIDRegex = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+var exportNameRegex = regexp.MustCompile(`^[a-zA-Z0-9:-]+$`)
+
+// validateLimits checks if the template fits in CloudFormation service limits. Template body size is checked,
+// because perun passes templates inline as TemplateBody.
+func validateLimits(tmpl template.Template, templateBodySize int, limits configuration.TemplateLimits, sink logger.LoggerInt) bool {
+	valid := true
+	addError := func(elementName string, e pathError) {
+		sink.AddResourceForValidation(elementName).AddError(e.path, e.String())
+		valid = false
+	}
+
+	if templateBodySize > limits.MaxTemplateBodySize {
+		addError("Template", pathError{"", "Template body has " + strconv.Itoa(templateBodySize) + " bytes, but maximum size is " +
+			strconv.Itoa(limits.MaxTemplateBodySize) + " bytes"})
+	}
+
+	sections := []struct {
+		name  string
+		names []string
+		limit int
+	}{
+		{"Resources", sortedResourceNames(tmpl.Resources), limits.MaxResources},
+		{"Outputs", sortedKeys(tmpl.Outputs), limits.MaxOutputs},
+		{"Parameters", sortedKeys(tmpl.Parameters), limits.MaxParameters},
+		{"Mappings", sortedKeys(tmpl.Mappings), limits.MaxMappings},
+		{"Conditions", sortedKeys(tmpl.Conditions), 0},
+	}
+	for _, section := range sections {
+		if section.limit > 0 && len(section.names) > section.limit {
+			addError(section.name, pathError{"", "Template has " + strconv.Itoa(len(section.names)) + " " + section.name +
+				", but maximum number of " + section.name + " is " + strconv.Itoa(section.limit)})
+		}
+		for _, logicalID := range section.names {
+			if message := checkLogicalID(logicalID, limits.MaxLogicalIDLength); message != "" {
+				addError(section.name, pathError{logicalID, message})
+			}
+		}
+	}
+
+	for _, mappingName := range sortedKeys(tmpl.Mappings) {
+		if mapping, ok := tmpl.Mappings[mappingName].(map[string]interface{}); ok && len(mapping) > limits.MaxMappingAttributes {
+			addError("Mappings", pathError{mappingName, "Mapping has " + strconv.Itoa(len(mapping)) +
+				" attributes, but maximum number of attributes is " + strconv.Itoa(limits.MaxMappingAttributes)})
+		}
+	}
+
+	exports := map[string]string{}
+	for _, outputName := range sortedKeys(tmpl.Outputs) {
+		exportName, ok := getExportName(tmpl.Outputs[outputName])
+		if !ok {
+			continue
+		}
+		if len(exportName) > limits.MaxExportNameLength {
+			addError("Outputs", pathError{outputName, "Export name is longer than " + strconv.Itoa(limits.MaxExportNameLength) + " characters"})
+		}
+		if !exportNameRegex.MatchString(exportName) {
+			addError("Outputs", pathError{outputName, "Export name " + exportName + " can contain only alphanumeric characters, colons and hyphens"})
+		}
+		if otherOutput, exported := exports[exportName]; exported {
+			addError("Outputs", pathError{outputName, "Export name " + exportName + " is already used by output " + otherOutput})
+		} else {
+			exports[exportName] = outputName
+		}
+	}
+	return valid
+}
+
+func checkLogicalID(logicalID string, maxLength int) string {
+	if len(logicalID) > maxLength {
+		return "Logical ID is longer than " + strconv.Itoa(maxLength) + " characters"
+	}
+	if !logicalIDRegex.MatchString(logicalID) {
+		return "Logical ID can contain only alphanumeric characters (A-Za-z0-9)"
+	}
+	return ""
+}
+
+// getExportName returns export name of the output, if it's a literal.
+func getExportName(output interface{}) (string, bool) {
+	outputMap, _ := output.(map[string]interface{})
+	export, _ := outputMap["Export"].(map[string]interface{})
+	name, ok := export["Name"].(string)
+	return name, ok
+}
@@ -0,0 +1,125 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/Appliscale/perun/configuration"
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateWithinLimits(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Parameters:
+  Environment:
+    Type: String
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+Outputs:
+  BucketName:
+    Value: !Ref Bucket
+    Export:
+      Name: perun:bucket-name
+`)
+
+	assert.True(t, validateLimits(tmpl, 1024, configuration.DefaultTemplateLimits, &sink))
+}
+
+func TestTemplateBodyTooLarge(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+
+	assert.False(t, validateLimits(template.Template{}, 51201, configuration.DefaultTemplateLimits, &sink))
+	assert.Equal(t, []string{"Template body has 51201 bytes, but maximum size is 51200 bytes"},
+		sink.AddResourceForValidation("Template").Errors)
+}
+
+func TestTooManyParameters(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	parameters := template.Parameters{}
+	for i := 0; i <= configuration.DefaultTemplateLimits.MaxParameters; i++ {
+		parameters["Parameter"+strconv.Itoa(i)] = map[string]interface{}{"Type": "String"}
+	}
+
+	assert.False(t, validateLimits(template.Template{Parameters: parameters}, 0, configuration.DefaultTemplateLimits, &sink))
+	assert.Equal(t, []string{"Template has 201 Parameters, but maximum number of Parameters is 200"},
+		sink.AddResourceForValidation("Parameters").Errors)
+}
+
+func TestConfiguredLimits(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	limits := configuration.DefaultTemplateLimits
+	limits.MaxResources = 1
+	tmpl := template.Template{
+		Resources: map[string]template.Resource{
+			"First":  {Type: "AWS::S3::Bucket"},
+			"Second": {Type: "AWS::S3::Bucket"},
+		},
+	}
+
+	assert.False(t, validateLimits(tmpl, 0, limits, &sink))
+	assert.Equal(t, []string{"Template has 2 Resources, but maximum number of Resources is 1"},
+		sink.AddResourceForValidation("Resources").Errors)
+}
+
+func TestInvalidLogicalIDs(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	limits := configuration.DefaultTemplateLimits
+	limits.MaxLogicalIDLength = 10
+	tmpl := template.Template{
+		Resources: map[string]template.Resource{
+			"My-Bucket":            {Type: "AWS::S3::Bucket"},
+			"VeryLongResourceName": {Type: "AWS::S3::Bucket"},
+		},
+	}
+
+	assert.False(t, validateLimits(tmpl, 0, limits, &sink))
+	assert.Equal(t, []string{
+		"My-Bucket: Logical ID can contain only alphanumeric characters (A-Za-z0-9)",
+		"VeryLongResourceName: Logical ID is longer than 10 characters",
+	}, sink.AddResourceForValidation("Resources").Errors)
+}
+
+func TestInvalidExportNames(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Outputs:
+  First:
+    Value: first
+    Export:
+      Name: perun/export
+  Second:
+    Value: second
+    Export:
+      Name: perun-export
+  Third:
+    Value: third
+    Export:
+      Name: perun-export
+`)
+
+	assert.False(t, validateLimits(tmpl, 0, configuration.DefaultTemplateLimits, &sink))
+	assert.Equal(t, []string{
+		"First: Export name perun/export can contain only alphanumeric characters, colons and hyphens",
+		"Third: Export name perun-export is already used by output Second",
+	}, sink.AddResourceForValidation("Outputs").Errors)
+}
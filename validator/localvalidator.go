@@ -23,10 +23,9 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"os"
-	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 
 	"errors"
 
@@ -35,9 +34,11 @@ import (
 	"github.com/Appliscale/perun/helpers"
 	"github.com/Appliscale/perun/logger"
 	"github.com/Appliscale/perun/specification"
+	"github.com/Appliscale/perun/validator/parsers"
 	"github.com/Appliscale/perun/validator/template"
 	"github.com/Appliscale/perun/validator/validators"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/awslabs/goformation"
@@ -46,7 +47,29 @@ import (
 )
 
 var validatorsMap = map[string]interface{}{
-	"AWS::EC2::VPC": validators.IsVpcValid,
+	"AWS::EC2::VPC":                  validators.IsVpcValid,
+	"AWS::EC2::VPCCidrBlock":         validators.IsVpcCidrBlockValid,
+	"AWS::EC2::Subnet":               validators.IsSubnetValid,
+	"AWS::EC2::SecurityGroup":        validators.IsSecurityGroupValid,
+	"AWS::EC2::SecurityGroupIngress": validators.IsSecurityGroupIngressValid,
+	"AWS::EC2::SecurityGroupEgress":  validators.IsSecurityGroupEgressValid,
+	"AWS::IAM::Policy":               validators.IsPolicyDocumentValid,
+	"AWS::IAM::ManagedPolicy":        validators.IsPolicyDocumentValid,
+	"AWS::IAM::RolePolicy":           validators.IsPolicyDocumentValid,
+	"AWS::IAM::UserPolicy":           validators.IsPolicyDocumentValid,
+	"AWS::IAM::GroupPolicy":          validators.IsPolicyDocumentValid,
+	"AWS::IAM::Role":                 validators.IsPolicyDocumentValid,
+	"AWS::IAM::User":                 validators.IsPolicyDocumentValid,
+	"AWS::IAM::Group":                validators.IsPolicyDocumentValid,
+	"AWS::DynamoDB::Table":           validators.IsDynamoDBTableValid,
+	"AWS::Lambda::Function":          validators.IsLambdaFunctionValid,
+	"AWS::S3::BucketPolicy":          validators.IsPolicyDocumentValid,
+	"AWS::SQS::QueuePolicy":          validators.IsPolicyDocumentValid,
+	"AWS::SNS::TopicPolicy":          validators.IsPolicyDocumentValid,
+	"AWS::KMS::Key":                  validators.IsPolicyDocumentValid,
+	"AWS::ECR::Repository":           validators.IsPolicyDocumentValid,
+	"AWS::Elasticsearch::Domain":     validators.IsPolicyDocumentValid,
+	"AWS::OpenSearchService::Domain": validators.IsPolicyDocumentValid,
 }
 
 func printResult(templateName string, valid *bool, logger logger.LoggerInt) {
@@ -60,21 +83,48 @@ func printResult(templateName string, valid *bool, logger logger.LoggerInt) {
 	}
 }
 
-// Validate CloudFormation template.
+// Validate CloudFormation templates. Many templates, directories and glob patterns can be given for validate mode,
+// then they are validated concurrently.
 func Validate(ctx *context.Context) bool {
-	return validateTemplateFile(*ctx.CliArguments.TemplatePath, *ctx.CliArguments.TemplatePath, ctx)
-}
+	templatePaths, err := helpers.FindTemplateFiles(getTemplatePaths(ctx))
+	if err != nil {
+		ctx.Logger.Error(err.Error())
+		return false
+	}
 
-func validateTemplateFile(templatePath string, templateName string, context *context.Context) (valid bool) {
-	valid = false
-	defer printResult(templateName, &valid, context.Logger)
+	resourceSpecification, err := specification.GetSpecification(ctx)
+	if err != nil {
+		ctx.Logger.Error(err.Error())
+		if len(templatePaths) == 1 {
+			printResult(templatePaths[0], new(bool), ctx.Logger)
+		}
+		return false
+	}
 
-	resourceSpecification, err := specification.GetSpecification(context)
+	if len(templatePaths) == 1 {
+		return validateTemplateFile(templatePaths[0], templatePaths[0], &resourceSpecification, ctx)
+	}
+	return validateTemplates(templatePaths, &resourceSpecification, ctx)
+}
 
-	if err != nil {
-		context.Logger.Error(err.Error())
-		return
+func getTemplatePaths(ctx *context.Context) []string {
+	if ctx.CliArguments.TemplatePaths != nil && len(*ctx.CliArguments.TemplatePaths) > 0 {
+		return *ctx.CliArguments.TemplatePaths
 	}
+	return []string{*ctx.CliArguments.TemplatePath}
+}
+
+// validateTemplateFile validates the template and prints results.
+func validateTemplateFile(templatePath string, templateName string, resourceSpecification *specification.Specification, context *context.Context) bool {
+	valid := checkTemplateFile(templatePath, templateName, nil, resourceSpecification, context)
+	printResult(templateName, &valid, context.Logger)
+	return valid
+}
+
+// checkTemplateFile validates the template. Results are collected by the logger, but they are not printed.
+// Parent templates are templates which include the template as a nested stack, starting from the top one.
+func checkTemplateFile(templatePath string, templateName string, parentTemplates []string, resourceSpecification *specification.Specification, context *context.Context) (valid bool) {
+	valid = false
 
 	rawTemplate, err := ioutil.ReadFile(templatePath)
 	if err != nil {
@@ -85,7 +135,7 @@ func validateTemplateFile(templatePath string, templateName string, context *con
 	var perunTemplate template.Template
 	var goFormationTemplate cloudformation.Template
 
-	parser, err := helpers.GetParser(*context.CliArguments.TemplatePath)
+	parser, err := helpers.GetParser(templateName, context.DownloadSnippet)
 	if err != nil {
 		context.Logger.Error(err.Error())
 		return
@@ -95,59 +145,43 @@ func validateTemplateFile(templatePath string, templateName string, context *con
 		context.Logger.Error(err.Error())
 		return
 	}
+	unresolvedTemplate, err := helpers.ParseTemplate(templateName, rawTemplate, context.DownloadSnippet, context.Logger)
+	if err != nil {
+		context.Logger.Error(err.Error())
+		return
+	}
 
 	deNilizedTemplate, _ := nilNeutralize(goFormationTemplate, context.Logger)
 	resources := obtainResources(deNilizedTemplate, perunTemplate, context.Logger)
 	deadResources := getNilResources(resources)
 	deadProperties := getNilProperties(resources)
-	if hasAllowedValuesParametersValid(goFormationTemplate.Parameters) {
-		valid = true
-	} else {
-		valid = false
-		context.Logger.AddResourceForValidation("Parameters").AddValidationError("Allowed Values supports only Type String")
-	}
+	valid = validateParameters(unresolvedTemplate.Parameters, context.Logger)
 
 	specInconsistency := context.InconsistencyConfig.SpecificationInconsistency
 
 	templateBody := string(rawTemplate)
-	valid = validateResources(resources, &resourceSpecification, deadProperties, deadResources, specInconsistency, context) && valid
-	valid = awsValidate(context, &templateBody) && valid
-
-	return valid
-}
-
-// Looking for AllowedValues and checking what Type is it. If it finds Type other than String then it will return false.
-func hasAllowedValuesParametersValid(parameters template.Parameters) bool {
-	isType := false
-	isAllovedValues := false
-	for _, value := range parameters {
-		valueof := reflect.ValueOf(value)
-		isAllovedValues = false
-		isType = false
-
-		for _, key := range valueof.MapKeys() {
-
-			keyValue := valueof.MapIndex(key)
-			textType := "Type"
-			keyString := key.Interface().(string)
-			textValues := "AllowedValues"
-
-			if textType == keyString {
-				textString := "String"
-				keyValueString := keyValue.Interface().(string)
-				if textString != keyValueString {
-					isType = true
-				}
-			} else if textValues == keyString {
-				isAllovedValues = true
-			}
+	valid = validateResources(resources, resourceSpecification, deadProperties, deadResources, specInconsistency, context) && valid
+	valid = validateNestedStacks(templateName, parentTemplates, unresolvedTemplate, resourceSpecification, context) && valid
+	valid = validateReferences(unresolvedTemplate, resourceSpecification, context.Logger) && valid
+	valid = validators.ValidateNetworkTopology(unresolvedTemplate.Resources, context.Logger) && valid
+	valid = validateResourceAttributes(unresolvedTemplate, context.Logger) && valid
+	valid = validateDependencies(unresolvedTemplate, context.Logger) && valid
+	valid = validateConditions(unresolvedTemplate, context.Logger) && valid
+	valid = validateLimits(unresolvedTemplate, len(rawTemplate), context.Config.GetTemplateLimits(), context.Logger) && valid
+	valid = validateMappings(unresolvedTemplate, context.Config.DefaultRegion, sortedRegions(context.Config.SpecificationURL), context.Logger) && valid
+	if context.IsOffline() {
+		context.Logger.Info("Offline mode - template is not validated with AWS CloudFormation API")
+	} else {
+		valid = awsValidate(context, &templateBody) && valid
+	}
 
-			if isAllovedValues && isType {
-				return false
-			}
-		}
+	var templateWithDetails template.TemplateWithDetails
+	if err := parsers.ParseWithDetails(templateName, rawTemplate, &templateWithDetails); err != nil {
+		context.Logger.Debug("Could not find positions of validation messages: " + err.Error())
 	}
-	return true
+	locateValidationMessages(templateWithDetails, templateName, context.Logger)
+
+	return valid
 }
 
 func validateResources(resources map[string]template.Resource, specification *specification.Specification, deadProp []string, deadRes []string, specInconsistency map[string]configuration.Property, ctx *context.Context) bool {
@@ -155,9 +189,9 @@ func validateResources(resources map[string]template.Resource, specification *sp
 	for resourceName, resourceValue := range resources {
 		if deadResource := helpers.SliceContains(deadRes, resourceName); !deadResource {
 			resourceValidation := sink.AddResourceForValidation(resourceName)
-			processNestedTemplates(resourceValue.Properties, ctx)
 			validators.GeneralValidateResourceByName(resourceValue, resourceValidation, ctx)
 			if resourceSpecification, ok := specification.ResourceTypes[resourceValue.Type]; ok {
+				checkUnknownProperties(resourceValue.Properties, resourceSpecification.Properties, "Properties", "resource type "+resourceValue.Type, resourceValidation)
 				for propertyName, propertyValue := range resourceSpecification.Properties {
 					if deadProperty := helpers.SliceContains(deadProp, propertyName); !deadProperty {
 						validateProperties(specification, resourceValue, propertyName, propertyValue, resourceValidation, specInconsistency, sink)
@@ -187,16 +221,36 @@ func validateProperties(
 	warnAboutSpecificationInconsistencies(propertyName, specInconsistency[resourceValue.Type], logger)
 	if _, ok := resourceValue.Properties[propertyName]; !ok {
 		if propertyValue.Required {
-			resourceValidation.AddValidationError("Property " + propertyName + " is required")
+			resourceValidation.AddError("Properties", "Property "+propertyName+" is required")
 		}
 	} else if len(propertyValue.Type) > 0 {
 		if propertyValue.Type != "List" && propertyValue.Type != "Map" {
-			checkNestedProperties(specification, resourceValue.Properties, resourceValue.Type, propertyName, propertyValue.Type, resourceValidation, specInconsistency, logger)
+			checkNestedProperties(specification, resourceValue.Properties, "Properties", resourceValue.Type, propertyName, propertyValue.Type, resourceValidation, specInconsistency, logger)
 		} else if propertyValue.Type == "List" {
-			checkListProperties(specification, resourceValue.Properties, resourceValue.Type, propertyName, propertyValue.ItemType, resourceValidation, specInconsistency, logger)
+			checkListProperties(specification, resourceValue.Properties, "Properties", resourceValue.Type, propertyName, propertyValue.ItemType, propertyValue.PrimitiveItemType, resourceValidation, specInconsistency, logger)
 		} else if propertyValue.Type == "Map" {
-			checkMapProperties(resourceValue.Properties, propertyName, resourceValidation)
+			checkMapProperties(resourceValue.Properties, "Properties", propertyName, propertyValue.PrimitiveItemType, resourceValidation)
 		}
+	} else if len(propertyValue.PrimitiveType) > 0 {
+		checkPrimitiveProperty(resourceValue.Properties, "Properties", propertyName, propertyValue.PrimitiveType, resourceValidation)
+	}
+}
+
+// checkUnknownProperties warns about properties which are not in the specification, suggesting the most similar valid name.
+// Path is the path of the properties map inside the resource.
+func checkUnknownProperties(properties map[string]interface{}, specProperties map[string]specification.Property, path string, location string, resourceValidation *logger.ResourceValidation) {
+	if _, isFunction := isIntrinsicFunction(properties); isFunction {
+		return
+	}
+	validNames := make([]string, 0, len(specProperties))
+	for name := range specProperties {
+		validNames = append(validNames, name)
+	}
+	for name := range properties {
+		if _, ok := specProperties[name]; ok {
+			continue
+		}
+		resourceValidation.AddWarning(path+"."+name, "Property "+name+" is not supported in "+location+suggestion(name, validNames))
 	}
 }
 
@@ -214,30 +268,43 @@ func warnAboutSpecificationInconsistencies(subpropertyName string, specInconsist
 func checkListProperties(
 	spec *specification.Specification,
 	resourceProperties map[string]interface{},
-	resourceValueType, propertyName, listItemType string,
+	path, resourceValueType, propertyName, listItemType, primitiveItemType string,
 	resourceValidation *logger.ResourceValidation,
 	specInconsistency map[string]configuration.Property,
 	logger logger.LoggerInt) {
 
+	propertyPath := path + "." + propertyName
 	if listItemType == "" {
-		resourceSubproperties := toStringList(resourceProperties, propertyName)
-		if reflect.TypeOf(resourceSubproperties).Kind() != reflect.Slice || len(resourceSubproperties) == 0 {
-			resourceValidation.AddValidationError(propertyName + " must be a List")
+		resourceSubproperties, isList := resourceProperties[propertyName].([]interface{})
+		if !isList {
+			checkListValue(propertyPath, propertyName, resourceProperties[propertyName], resourceValidation)
+		} else if len(resourceSubproperties) == 0 {
+			resourceValidation.AddError(propertyPath, propertyName+" must be a List")
+		} else {
+			checkPrimitiveItems(resourceProperties, path, propertyName, primitiveItemType, resourceValidation)
 		}
-	} else if propertySpec, hasSpec := spec.PropertyTypes[resourceValueType+"."+listItemType]; hasSpec {
+	} else if propertySpec, hasSpec := spec.GetPropertyType(resourceValueType + "." + listItemType); hasSpec {
 		resourceSubproperties := toMapList(resourceProperties, propertyName)
+		for index, listItem := range resourceSubproperties {
+			checkUnknownProperties(listItem, propertySpec.Properties, listItemPath(propertyPath, index), listItemType, resourceValidation)
+		}
 		for subpropertyName, subpropertyValue := range propertySpec.Properties {
-			for _, listItem := range resourceSubproperties {
+			for index, listItem := range resourceSubproperties {
+				itemPath := listItemPath(propertyPath, index)
 				warnAboutSpecificationInconsistencies(subpropertyName, specInconsistency[resourceValueType+"."+listItemType], logger)
 				if _, isPresent := listItem[subpropertyName]; !isPresent {
 					if subpropertyValue.Required {
-						resourceValidation.AddValidationError("Property " + subpropertyName + " is required in " + listItemType)
+						resourceValidation.AddError(itemPath, "Property "+subpropertyName+" is required in "+listItemType)
 					}
 				} else if isPresent {
 					if subpropertyValue.IsSubproperty() {
-						checkNestedProperties(spec, listItem, resourceValueType, subpropertyName, subpropertyValue.Type, resourceValidation, specInconsistency, logger)
+						checkNestedProperties(spec, listItem, itemPath, resourceValueType, subpropertyName, subpropertyValue.Type, resourceValidation, specInconsistency, logger)
+					} else if subpropertyValue.Type == "List" {
+						checkListProperties(spec, listItem, itemPath, resourceValueType, subpropertyName, subpropertyValue.ItemType, subpropertyValue.PrimitiveItemType, resourceValidation, specInconsistency, logger)
 					} else if subpropertyValue.Type == "Map" {
-						checkMapProperties(listItem, propertyName, resourceValidation)
+						checkMapProperties(listItem, itemPath, subpropertyName, subpropertyValue.PrimitiveItemType, resourceValidation)
+					} else if len(subpropertyValue.PrimitiveType) > 0 {
+						checkPrimitiveProperty(listItem, itemPath, subpropertyName, subpropertyValue.PrimitiveType, resourceValidation)
 					}
 				}
 			}
@@ -245,81 +312,58 @@ func checkListProperties(
 	}
 }
 
+func listItemPath(path string, index int) string {
+	return path + "[" + strconv.Itoa(index) + "]"
+}
+
 func checkNestedProperties(
 	spec *specification.Specification,
 	resourceProperties map[string]interface{},
-	resourceValueType, propertyName, propertyType string,
+	path, resourceValueType, propertyName, propertyType string,
 	resourceValidation *logger.ResourceValidation,
 	specInconsistency map[string]configuration.Property,
 	logger logger.LoggerInt) {
 
-	if propertySpec, hasSpec := spec.PropertyTypes[resourceValueType+"."+propertyType]; hasSpec {
+	if propertySpec, hasSpec := spec.GetPropertyType(resourceValueType + "." + propertyType); hasSpec {
+		propertyPath := path + "." + propertyName
 		resourceSubproperties, _ := toMap(resourceProperties, propertyName)
+		checkUnknownProperties(resourceSubproperties, propertySpec.Properties, propertyPath, propertyName, resourceValidation)
 		for subpropertyName, subpropertyValue := range propertySpec.Properties {
 			warnAboutSpecificationInconsistencies(subpropertyName, specInconsistency[resourceValueType+"."+propertyName], logger)
 			if _, isPresent := resourceSubproperties[subpropertyName]; !isPresent {
 				if subpropertyValue.Required {
-					resourceValidation.AddValidationError("Property " + subpropertyName + " is required " + "in " + propertyName)
+					resourceValidation.AddError(propertyPath, "Property "+subpropertyName+" is required "+"in "+propertyName)
 				}
 			} else if isPresent {
 				if subpropertyValue.IsSubproperty() {
-					checkNestedProperties(spec, resourceSubproperties, resourceValueType, subpropertyName, subpropertyValue.Type, resourceValidation, specInconsistency, logger)
+					checkNestedProperties(spec, resourceSubproperties, propertyPath, resourceValueType, subpropertyName, subpropertyValue.Type, resourceValidation, specInconsistency, logger)
 				} else if subpropertyValue.Type == "List" {
-					checkListProperties(spec, resourceSubproperties, resourceValueType, subpropertyName, subpropertyValue.ItemType, resourceValidation, specInconsistency, logger)
+					checkListProperties(spec, resourceSubproperties, propertyPath, resourceValueType, subpropertyName, subpropertyValue.ItemType, subpropertyValue.PrimitiveItemType, resourceValidation, specInconsistency, logger)
 				} else if subpropertyValue.Type == "Map" {
-					checkMapProperties(resourceSubproperties, subpropertyName, resourceValidation)
+					checkMapProperties(resourceSubproperties, propertyPath, subpropertyName, subpropertyValue.PrimitiveItemType, resourceValidation)
+				} else if len(subpropertyValue.PrimitiveType) > 0 {
+					checkPrimitiveProperty(resourceSubproperties, propertyPath, subpropertyName, subpropertyValue.PrimitiveType, resourceValidation)
 				}
 			}
 		}
 	}
 }
 
-func processNestedTemplates(properties map[string]interface{}, ctx *context.Context) {
-	if rawTemplateURL, ok := properties["TemplateURL"]; ok {
-		if templateURL, ok := rawTemplateURL.(string); ok {
-			err := validateNestedTemplate(templateURL, ctx)
-			if err != nil {
-				ctx.Logger.Error(err.Error())
-				os.Exit(1)
-			}
-		}
-	}
-}
-
-func validateNestedTemplate(templateURL string, ctx *context.Context) error {
-	err := context.UpdateSessionToken(ctx.Config.DefaultProfile, ctx.Config.DefaultRegion, ctx.Config.DefaultDurationForMFA, ctx)
+func downloadTemplateFromBucket(templateURL string, file io.WriterAt, ctx *context.Context) error {
+	region, bucket, key, err := fetchBucketDataFromURL(templateURL)
 	if err != nil {
 		return err
 	}
-
-	tempfile, err := ioutil.TempFile(ctx.Config.DefaultTemporaryFilesDirectory, "")
-	if err != nil {
-		return err
+	if region == "" {
+		region = ctx.Config.DefaultRegion
 	}
-	defer os.Remove(tempfile.Name())
-
-	if err := downloadTemplateFromBucket(templateURL, tempfile, ctx); err != nil {
-		return err
-	}
-
-	validateTemplateFile(tempfile.Name(), templateURL, ctx)
-
-	if err = tempfile.Close(); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func downloadTemplateFromBucket(templateURL string, file io.WriterAt, ctx *context.Context) error {
-	region, bucket, key := fetchBucketDataFromURL(templateURL)
 
-	session, err := context.CreateSession(ctx, ctx.Config.DefaultProfile, &region)
+	bucketSession, err := createBucketSession(region, ctx)
 	if err != nil {
 		return err
 	}
 
-	downloader := s3manager.NewDownloader(session)
+	downloader := s3manager.NewDownloader(bucketSession)
 
 	_, err = downloader.Download(file, &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
@@ -332,8 +376,36 @@ func downloadTemplateFromBucket(templateURL string, file io.WriterAt, ctx *conte
 	return nil
 }
 
-func fetchBucketDataFromURL(url string) (region string, bucket string, key string) {
+// sessionMutex serializes updating the session token, which may ask for MFA token, and creating sessions
+// when templates are validated concurrently.
+var sessionMutex sync.Mutex
+
+func createBucketSession(region string, ctx *context.Context) (*session.Session, error) {
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+
+	err := context.UpdateSessionToken(ctx.Config.DefaultProfile, ctx.Config.DefaultRegion, ctx.Config.DefaultDurationForMFA, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return context.CreateSession(ctx, ctx.Config.DefaultProfile, &region)
+}
+
+// fetchBucketDataFromURL splits S3 URL into region, bucket and key. Region is empty for s3://<bucket>/<key> URLs.
+func fetchBucketDataFromURL(url string) (region string, bucket string, key string, err error) {
+	if strings.HasPrefix(url, "s3://") {
+		path := strings.SplitN(strings.TrimPrefix(url, "s3://"), "/", 2)
+		if len(path) < 2 || path[0] == "" || path[1] == "" {
+			err = errors.New("Invalid S3 URL " + url + ", it should be s3://<bucket>/<key>")
+			return
+		}
+		return "", path[0], path[1], nil
+	}
 	path := strings.SplitN(url, "/", 5)
+	if len(path) < 5 || len(strings.Split(path[2], ".")) < 2 {
+		err = errors.New("Invalid S3 URL " + url + ", it should be https://s3.<region>.amazonaws.com/<bucket>/<key>")
+		return
+	}
 	host := strings.Split(path[2], ".")
 
 	region = host[1]
@@ -344,12 +416,14 @@ func fetchBucketDataFromURL(url string) (region string, bucket string, key strin
 
 func checkMapProperties(
 	resourceProperties map[string]interface{},
-	propertyName string,
+	path, propertyName, primitiveItemType string,
 	resourceValidation *logger.ResourceValidation) {
 
 	_, err := toMap(resourceProperties, propertyName)
 	if err != nil {
-		resourceValidation.AddValidationError(err.Error())
+		resourceValidation.AddError(path+"."+propertyName, err.Error())
+	} else {
+		checkPrimitiveItems(resourceProperties, path, propertyName, primitiveItemType, resourceValidation)
 	}
 }
 
@@ -388,21 +462,6 @@ func toMapList(resourceProperties map[string]interface{}, propertyName string) [
 	return mapList
 }
 
-func toStringList(resourceProperties map[string]interface{}, propertyName string) []string {
-	subproperties, ok := resourceProperties[propertyName].([]interface{})
-	if !ok {
-		return nil
-	}
-
-	list := make([]string, len(subproperties))
-	for index, value := range subproperties {
-		if value != nil {
-			list[index] = value.(string)
-		}
-	}
-	return list
-}
-
 func toMap(resourceProperties map[string]interface{}, propertyName string) (map[string]interface{}, error) {
 	subproperties, ok := resourceProperties[propertyName].(map[string]interface{})
 	if !ok {
@@ -341,26 +341,160 @@ func TestInvalidMapProperty(t *testing.T) {
 	assert.False(t, validateResources(resources, &spec, deadProp, deadRes, specInconsistency, &mockContext), "This resource should be valid")
 }
 
-func TestHasAllowedValuesParametersValid(t *testing.T) {
-	_ = logger.Logger{}
-	data := make(map[string]interface{})
+func TestValidPrimitiveTypes(t *testing.T) {
+	mockContext.Logger = &logger.Logger{}
+
+	resources := make(map[string]template.Resource)
+	resource := template.Resource{}
+	resource.Type = "AWS::Primitive1::Function"
+	resource.Properties = map[string]interface{}{
+		"MemorySize": "128",
+		"Enabled":    true,
+		"Ratio":      0.5,
+		"Expiration": "2018-06-01T12:00:00Z",
+		"Ports":      []interface{}{80, 443.0},
+		"Limits": map[string]interface{}{
+			"Max": "100",
+		},
+	}
+	resources["ExampleResource"] = resource
+
+	assert.True(t, validateResources(resources, &spec, deadProp, deadRes, specInconsistency, &mockContext), "This resource should be valid")
+}
+
+func TestInvalidIntegerProperty(t *testing.T) {
+	mockContext.Logger = &logger.Logger{}
+
+	resources := make(map[string]template.Resource)
+	resources["ExampleResource"] = createResourceWithOneProperty("AWS::Primitive1::Function", "MemorySize", "abc")
+
+	assert.False(t, validateResources(resources, &spec, deadProp, deadRes, specInconsistency, &mockContext), "This resource shouldn't be valid - MemorySize should be an Integer")
+}
+
+func TestInvalidBooleanProperty(t *testing.T) {
+	mockContext.Logger = &logger.Logger{}
+
+	resources := make(map[string]template.Resource)
+	resources["ExampleResource"] = createResourceWithOneProperty("AWS::Primitive1::Function", "Enabled", "maybe")
+
+	assert.False(t, validateResources(resources, &spec, deadProp, deadRes, specInconsistency, &mockContext), "This resource shouldn't be valid - Enabled should be a Boolean")
+}
 
-	data["AllowedValues"] = ""
-	data["Type"] = "String"
-	parameters := createParameters("Correct", data)
+func TestInvalidTimestampProperty(t *testing.T) {
+	mockContext.Logger = &logger.Logger{}
+
+	resources := make(map[string]template.Resource)
+	resources["ExampleResource"] = createResourceWithOneProperty("AWS::Primitive1::Function", "Expiration", "tomorrow")
+
+	assert.False(t, validateResources(resources, &spec, deadProp, deadRes, specInconsistency, &mockContext), "This resource shouldn't be valid - Expiration should be a Timestamp")
+}
+
+func TestInvalidPrimitiveListItem(t *testing.T) {
+	mockContext.Logger = &logger.Logger{}
+
+	resources := make(map[string]template.Resource)
+	resource := template.Resource{}
+	resource.Type = "AWS::Primitive1::Function"
+	resource.Properties = map[string]interface{}{
+		"Ports": []interface{}{80, "http"},
+	}
+	resources["ExampleResource"] = resource
 
-	assert.True(t, hasAllowedValuesParametersValid(parameters), "This template has AllowedValues with Type String")
+	assert.False(t, validateResources(resources, &spec, deadProp, deadRes, specInconsistency, &mockContext), "This resource shouldn't be valid - Ports should be a List of Integers")
 }
 
-func TestHasAllowedValuesParametersInvalid(t *testing.T) {
-	_ = logger.Logger{}
-	data := make(map[string]interface{})
+func TestInvalidPrimitiveMapItem(t *testing.T) {
+	mockContext.Logger = &logger.Logger{}
 
-	data["AllowedValues"] = ""
-	data["Type"] = "AWS::EC2::VPC::Id"
-	parameters := createParameters("Incorrect", data)
+	resources := make(map[string]template.Resource)
+	resource := template.Resource{}
+	resource.Type = "AWS::Primitive1::Function"
+	resource.Properties = map[string]interface{}{
+		"Limits": map[string]interface{}{
+			"Max": 1.5,
+		},
+	}
+	resources["ExampleResource"] = resource
+
+	assert.False(t, validateResources(resources, &spec, deadProp, deadRes, specInconsistency, &mockContext), "This resource shouldn't be valid - Limits should be a Map of Longs")
+}
+
+func TestIntrinsicFunctionInPrimitiveProperty(t *testing.T) {
+	mockContext.Logger = &logger.Logger{}
+
+	resources := make(map[string]template.Resource)
+	resource := template.Resource{}
+	resource.Type = "AWS::Primitive1::Function"
+	resource.Properties = map[string]interface{}{
+		"MemorySize": map[string]interface{}{"Ref": "MemoryParameter"},
+		"Ports": map[string]interface{}{
+			"Fn::Split": []interface{}{",", "80,443"},
+		},
+	}
+	resources["ExampleResource"] = resource
+
+	assert.True(t, validateResources(resources, &spec, deadProp, deadRes, specInconsistency, &mockContext), "This resource should be valid")
+}
+
+func TestListReturningIntrinsicFunctionInPrimitiveProperty(t *testing.T) {
+	mockContext.Logger = &logger.Logger{}
+
+	resources := make(map[string]template.Resource)
+	resource := template.Resource{}
+	resource.Type = "AWS::Primitive1::Function"
+	resource.Properties = map[string]interface{}{
+		"MemorySize": map[string]interface{}{"Fn::GetAZs": ""},
+	}
+	resources["ExampleResource"] = resource
+
+	assert.False(t, validateResources(resources, &spec, deadProp, deadRes, specInconsistency, &mockContext), "This resource shouldn't be valid - Fn::GetAZs returns a List")
+}
+
+func TestUnknownPropertyInResource(t *testing.T) {
+	sink := &logger.Logger{}
+	mockContext.Logger = sink
+
+	resources := make(map[string]template.Resource)
+	resources["ExampleResource"] = createResourceWithOneProperty("AWS::Primitive1::Function", "MemorySise", "128")
+
+	assert.True(t, validateResources(resources, &spec, deadProp, deadRes, specInconsistency, &mockContext), "Unknown properties should be reported as warnings")
+	assert.True(t, sink.HasValidationWarnings(), "Unknown property should be reported")
+}
+
+func TestUnknownPropertySuggestion(t *testing.T) {
+	resourceValidation := logger.ResourceValidation{ResourceName: "ExampleResource"}
+	properties := map[string]interface{}{
+		"HostNmae":         "dummy.example.com",
+		"HttpRedirectCode": "301",
+	}
+
+	redirectRule, _ := spec.GetPropertyType("AWS::List2::Bucket.RedirectRule")
+	checkUnknownProperties(properties, redirectRule.Properties, "Properties.RedirectRule", "RedirectRule", &resourceValidation)
+
+	assert.Equal(t, []string{"Property HostNmae is not supported in RedirectRule. Did you mean HostName?"}, resourceValidation.Warnings)
+	assert.Equal(t, []string{"Properties.RedirectRule.HostNmae"}, resourceValidation.WarningPaths)
+}
+
+func TestUnknownPropertyInListItem(t *testing.T) {
+	sink := &logger.Logger{}
+	mockContext.Logger = sink
+
+	resources := make(map[string]template.Resource)
+	properties := map[string]interface{}{
+		"RoutingRules": []interface{}{
+			0: map[string]interface{}{
+				"RedirectRule": map[string]interface{}{
+					"HostName":         "dummy1.example.com",
+					"HttpRedirectCode": "SomeValue1",
+				},
+				"RedirectRules": "SomeValue",
+			},
+		},
+	}
+	resources["ExampleResource"] = createResourceWithNestedProperties("AWS::List2::Bucket", "WebsiteConfiguration", properties)
 
-	assert.False(t, hasAllowedValuesParametersValid(parameters), "This template has AllowedValues with Type other than String")
+	validateResources(resources, &spec, deadProp, deadRes, specInconsistency, &mockContext)
+	assert.True(t, sink.HasValidationWarnings(), "Unknown property in list item should be reported")
 }
 
 func createResourceWithNestedProperties(resourceType string, propertyName string, nestedPropertyValue map[string]interface{}) template.Resource {
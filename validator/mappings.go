@@ -0,0 +1,271 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/Appliscale/perun/helpers"
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+)
+
+// functionCall describes usage of an intrinsic function found in the template.
+type functionCall struct {
+	Arguments interface{}
+	Path      string
+}
+
+// findFunctionCalls looks for all calls of the function in the value, including calls nested in other functions.
+func findFunctionCalls(value interface{}, path string, function string) (calls []functionCall) {
+	switch element := value.(type) {
+	case map[string]interface{}:
+		if name, isFunction := isIntrinsicFunction(element); isFunction && name == function {
+			calls = append(calls, functionCall{Arguments: element[name], Path: path})
+		}
+		for _, key := range sortedKeys(element) {
+			calls = append(calls, findFunctionCalls(element[key], joinPath(path, key), function)...)
+		}
+	case []interface{}:
+		for index, item := range element {
+			calls = append(calls, findFunctionCalls(item, path+"["+strconv.Itoa(index)+"]", function)...)
+		}
+	}
+	return
+}
+
+// checkMappingsStructure checks if every mapping is a two-level map with scalar or list leaves.
+func checkMappingsStructure(mappings map[string]interface{}) (errors []pathError) {
+	for _, mappingName := range sortedKeys(mappings) {
+		mapping, ok := mappings[mappingName].(map[string]interface{})
+		if !ok || len(mapping) == 0 {
+			errors = append(errors, pathError{mappingName, "Mapping has to be a non-empty map of top level keys"})
+			continue
+		}
+		for _, topLevelKey := range sortedKeys(mapping) {
+			entries, ok := mapping[topLevelKey].(map[string]interface{})
+			if !ok || len(entries) == 0 {
+				errors = append(errors, pathError{mappingName + "." + topLevelKey, "Top level key has to be a non-empty map of second level keys"})
+				continue
+			}
+			for _, secondLevelKey := range sortedKeys(entries) {
+				if !isMappingValue(entries[secondLevelKey]) {
+					errors = append(errors, pathError{mappingName + "." + topLevelKey + "." + secondLevelKey, "Mapping value has to be a string, number, boolean or a list of them"})
+				}
+			}
+		}
+	}
+	return
+}
+
+func isMappingValue(value interface{}) bool {
+	if list, isList := value.([]interface{}); isList {
+		for _, item := range list {
+			if !isScalar(item) {
+				return false
+			}
+		}
+		return true
+	}
+	return value != nil && isScalar(value)
+}
+
+// resolveMappingKey returns key value known before deployment - a literal or AWS::Region resolved to the region
+// where the stack will be created.
+func resolveMappingKey(key interface{}, region string) (resolved string, description string, ok bool) {
+	switch value := key.(type) {
+	case string:
+		return value, value, true
+	case map[string]interface{}:
+		if isRegionKey(value) && region != "" {
+			return region, region + " (AWS::Region)", true
+		}
+	}
+	return "", "", false
+}
+
+// checkFindInMap checks if a single Fn::FindInMap call resolves to an existing entry.
+func checkFindInMap(arguments interface{}, mappings map[string]interface{}, region string) string {
+	keys, ok := arguments.([]interface{})
+	if !ok || len(keys) != 3 {
+		return "Fn::FindInMap requires exactly 3 arguments: map name, top level key and second level key"
+	}
+
+	mappingName, isLiteral := keys[0].(string)
+	if !isLiteral {
+		return ""
+	}
+	mapping, exists := mappings[mappingName].(map[string]interface{})
+	if !exists {
+		return "Mapping " + mappingName + " is not defined" + suggestion(mappingName, sortedKeys(mappings))
+	}
+
+	topLevelKey, description, resolved := resolveMappingKey(keys[1], region)
+	if !resolved {
+		return ""
+	}
+	entries, exists := mapping[topLevelKey].(map[string]interface{})
+	if !exists {
+		return "Mapping " + mappingName + " has no top level key " + description + suggestion(topLevelKey, sortedKeys(mapping))
+	}
+
+	secondLevelKey, description, resolved := resolveMappingKey(keys[2], region)
+	if !resolved {
+		return ""
+	}
+	if _, exists := entries[secondLevelKey]; !exists {
+		return "Mapping " + mappingName + " has no second level key " + description + " under " + topLevelKey + suggestion(secondLevelKey, sortedKeys(entries))
+	}
+	return ""
+}
+
+// isRegionKey checks if the mapping key is Ref to AWS::Region.
+func isRegionKey(key interface{}) bool {
+	value, isMap := key.(map[string]interface{})
+	if !isMap {
+		return false
+	}
+	name, _ := isIntrinsicFunction(value)
+	return name == "Ref" && value[name] == "AWS::Region"
+}
+
+// checkRegionLookup checks a Fn::FindInMap call keyed by AWS::Region in every region the mapping is keyed by, besides
+// the region where the stack will be created, which is checked by checkFindInMap.
+func checkRegionLookup(arguments interface{}, mappings map[string]interface{}, region string) (warnings []string) {
+	keys, ok := arguments.([]interface{})
+	if !ok || len(keys) != 3 || !isRegionKey(keys[1]) {
+		return
+	}
+	mappingName, _ := keys[0].(string)
+	mapping, exists := mappings[mappingName].(map[string]interface{})
+	secondLevelKey, isLiteral := keys[2].(string)
+	if !exists || !isLiteral {
+		return
+	}
+	for _, mappingRegion := range sortedKeys(mapping) {
+		entries, isMap := mapping[mappingRegion].(map[string]interface{})
+		if !isMap || mappingRegion == region {
+			continue
+		}
+		if _, exists := entries[secondLevelKey]; !exists {
+			warnings = append(warnings, "Mapping "+mappingName+" has no second level key "+secondLevelKey+" under "+mappingRegion+
+				", so the stack can't be created in "+mappingRegion)
+		}
+	}
+	return
+}
+
+// validateMappings checks structure of Mappings and whether Fn::FindInMap lookups in Resources, Outputs and Conditions
+// resolve to existing entries. Region is the region where the stack will be created, knownRegions are used to find
+// misspelled and missing region keys.
+func validateMappings(tmpl template.Template, region string, knownRegions []string, sink logger.LoggerInt) bool {
+	valid := true
+	for _, e := range checkMappingsStructure(tmpl.Mappings) {
+		sink.AddResourceForValidation("Mappings").AddError(e.path, e.String())
+		valid = false
+	}
+
+	type lookup struct {
+		elementName string
+		call        functionCall
+	}
+	var lookups []lookup
+	find := func(elementName string, value interface{}, path string) {
+		for _, call := range findFunctionCalls(value, path, "Fn::FindInMap") {
+			lookups = append(lookups, lookup{elementName, call})
+		}
+	}
+	for _, resourceName := range sortedResourceNames(tmpl.Resources) {
+		find(resourceName, tmpl.Resources[resourceName].Properties, "Properties")
+	}
+	for _, outputName := range sortedKeys(tmpl.Outputs) {
+		find("Outputs", tmpl.Outputs[outputName], outputName)
+	}
+	for _, conditionName := range sortedKeys(tmpl.Conditions) {
+		find("Conditions", tmpl.Conditions[conditionName], conditionName)
+	}
+
+	regionMappings := map[string]bool{}
+	for _, lookup := range lookups {
+		if keys, ok := lookup.call.Arguments.([]interface{}); ok && len(keys) == 3 && isRegionKey(keys[1]) {
+			if mappingName, isLiteral := keys[0].(string); isLiteral {
+				regionMappings[mappingName] = true
+			}
+		}
+	}
+	for _, mappingName := range sortedKeys(tmpl.Mappings) {
+		for _, warning := range checkRegionKeys(mappingName, tmpl.Mappings[mappingName], knownRegions, regionMappings[mappingName]) {
+			sink.AddResourceForValidation("Mappings").AddWarning(warning.path, warning.String())
+		}
+	}
+
+	for _, lookup := range lookups {
+		path := lookup.call.Path
+		resourceValidation := sink.AddResourceForValidation(lookup.elementName)
+		if message := checkFindInMap(lookup.call.Arguments, tmpl.Mappings, region); message != "" {
+			resourceValidation.AddError(path, path+": "+message)
+			valid = false
+		}
+		for _, warning := range checkRegionLookup(lookup.call.Arguments, tmpl.Mappings, region) {
+			resourceValidation.AddWarning(path, path+": "+warning)
+		}
+	}
+	return valid
+}
+
+// checkRegionKeys checks top level keys of a mapping keyed by regions. It warns about keys which are not known
+// regions and, if the mapping is looked up by AWS::Region, about known regions which are missing.
+func checkRegionKeys(mappingName string, mapping interface{}, knownRegions []string, lookedUpByRegion bool) (warnings []pathError) {
+	entries, ok := mapping.(map[string]interface{})
+	if !ok {
+		return
+	}
+	keys := sortedKeys(entries)
+	isRegionMapping := false
+	for _, key := range keys {
+		if helpers.SliceContains(knownRegions, key) {
+			isRegionMapping = true
+			break
+		}
+	}
+	if !isRegionMapping && !lookedUpByRegion {
+		return
+	}
+	for _, key := range keys {
+		if !helpers.SliceContains(knownRegions, key) {
+			warnings = append(warnings, pathError{mappingName + "." + key, key + " is not a known region" + suggestion(key, knownRegions)})
+		}
+	}
+	if lookedUpByRegion {
+		for _, region := range knownRegions {
+			if _, exists := entries[region]; !exists {
+				warnings = append(warnings, pathError{mappingName, "Mapping has no top level key " + region + ", so the stack can't be created in " + region})
+			}
+		}
+	}
+	return
+}
+
+func sortedRegions(specificationURL map[string]string) []string {
+	regions := make([]string, 0, len(specificationURL))
+	for region := range specificationURL {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
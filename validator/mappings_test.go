@@ -0,0 +1,131 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"testing"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+var knownRegions = []string{"eu-central-1", "eu-west-1", "us-east-1"}
+
+const mappingsTemplate = `
+Mappings:
+  RegionMap:
+    eu-west-1:
+      HVM64: ami-0123
+      Zones: [a, b]
+    us-east-1:
+      HVM64: ami-4567
+      Zones: [a, b, c]
+Resources:
+  Instance:
+    Type: AWS::EC2::Instance
+    Properties:
+      ImageId: !FindInMap [RegionMap, !Ref "AWS::Region", HVM64]
+`
+
+func TestValidMappings(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, mappingsTemplate)
+
+	assert.True(t, validateMappings(tmpl, "eu-west-1", []string{"eu-west-1", "us-east-1"}, &sink))
+	assert.False(t, sink.HasValidationWarnings())
+}
+
+func TestFindInMapWithRegionChecksAllRegions(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Mappings:
+  RegionMap:
+    eu-west-1:
+      HVM64: ami-0123
+    us-east-1:
+      PV64: ami-4567
+Resources:
+  Instance:
+    Type: AWS::EC2::Instance
+    Properties:
+      ImageId: !FindInMap [RegionMap, !Ref "AWS::Region", HVM64]
+`)
+
+	assert.True(t, validateMappings(tmpl, "eu-west-1", knownRegions, &sink))
+	assert.Equal(t, []string{"RegionMap: Mapping has no top level key eu-central-1, so the stack can't be created in eu-central-1"},
+		sink.AddResourceForValidation("Mappings").Warnings)
+	assert.Equal(t, []string{"Properties.ImageId: Mapping RegionMap has no second level key HVM64 under us-east-1, so the stack can't be created in us-east-1"},
+		sink.AddResourceForValidation("Instance").Warnings)
+}
+
+func TestFindInMapWithMissingRegion(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, mappingsTemplate)
+
+	assert.False(t, validateMappings(tmpl, "eu-central-1", knownRegions, &sink))
+	assert.Equal(t, []string{"Properties.ImageId: Mapping RegionMap has no top level key eu-central-1 (AWS::Region)"},
+		sink.AddResourceForValidation("Instance").Errors)
+}
+
+func TestFindInMapWithMissingLiteralKeys(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Mappings:
+  RegionMap:
+    eu-west-1:
+      HVM64: ami-0123
+Outputs:
+  MissingMap:
+    Value: !FindInMap [RegionMpa, eu-west-1, HVM64]
+  MissingSecondKey:
+    Value: !FindInMap [RegionMap, eu-west-1, HVM65]
+  DynamicKey:
+    Value: !FindInMap [RegionMap, !Ref Environment, HVM64]
+`)
+
+	assert.False(t, validateMappings(tmpl, "eu-west-1", knownRegions, &sink))
+	assert.Equal(t, []string{
+		"MissingMap.Value: Mapping RegionMpa is not defined. Did you mean RegionMap?",
+		"MissingSecondKey.Value: Mapping RegionMap has no second level key HVM65 under eu-west-1. Did you mean HVM64?",
+	}, sink.AddResourceForValidation("Outputs").Errors)
+}
+
+func TestInvalidMappingsStructure(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Mappings:
+  Flat:
+    Key: Value
+  Deep:
+    eu-west-1:
+      Ami:
+        Id: ami-0123
+  Misspelled:
+    eu-west1:
+      Ami: ami-0123
+    us-east-1:
+      Ami: ami-4567
+`)
+
+	assert.False(t, validateMappings(tmpl, "eu-west-1", knownRegions, &sink))
+	assert.Equal(t, []string{
+		"Deep.eu-west-1.Ami: Mapping value has to be a string, number, boolean or a list of them",
+		"Flat.Key: Top level key has to be a non-empty map of second level keys",
+	}, sink.AddResourceForValidation("Mappings").Errors)
+	assert.Equal(t, []string{"Misspelled.eu-west1: eu-west1 is not a known region. Did you mean eu-west-1?"},
+		sink.AddResourceForValidation("Mappings").Warnings)
+}
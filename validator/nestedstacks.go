@@ -0,0 +1,283 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Appliscale/perun/context"
+	"github.com/Appliscale/perun/helpers"
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/specification"
+	"github.com/Appliscale/perun/validator/template"
+)
+
+// nestedLogger collects validation results of a nested template separately from results of the parent template,
+// while log messages are written by the parent's logger.
+type nestedLogger struct {
+	logger.LoggerInt
+	results logger.Logger
+}
+
+func (nested *nestedLogger) HasValidationErrors() bool {
+	return nested.results.HasValidationErrors()
+}
+
+func (nested *nestedLogger) HasValidationWarnings() bool {
+	return nested.results.HasValidationWarnings()
+}
+
+func (nested *nestedLogger) AddResourceForValidation(resourceName string) *logger.ResourceValidation {
+	return nested.results.AddResourceForValidation(resourceName)
+}
+
+func (nested *nestedLogger) GetResourceValidations() []*logger.ResourceValidation {
+	return nested.results.GetResourceValidations()
+}
+
+func (nested *nestedLogger) AppendResourceValidations(validations []*logger.ResourceValidation) {
+	nested.results.AppendResourceValidations(validations)
+}
+
+// nestedTemplate is a parsed template of a nested stack.
+type nestedTemplate struct {
+	name     string
+	template template.Template
+}
+
+// validateNestedStacks validates templates of nested stacks, which are stored locally (TemplateURL is a path relative
+// to the template) or in S3. Results of a nested template are added as results of elements named
+// <nested stack resource>/<element>. Parameters passed to nested stacks and their outputs used in the template
+// are checked too.
+func validateNestedStacks(templateName string, parentTemplates []string, tmpl template.Template, resourceSpecification *specification.Specification, ctx *context.Context) bool {
+	valid := true
+	templates := append(append([]string{}, parentTemplates...), getTemplateKey(templateName))
+	nestedTemplates := make(map[string]nestedTemplate)
+
+	for _, resourceName := range sortedResourceNames(tmpl.Resources) {
+		resource := tmpl.Resources[resourceName]
+		if resource.Type != "AWS::CloudFormation::Stack" {
+			continue
+		}
+		templateURL, ok := resource.Properties["TemplateURL"].(string)
+		if !ok {
+			continue
+		}
+		resourceValidation := ctx.Logger.AddResourceForValidation(resourceName)
+
+		var nestedValid bool
+		var nested *nestedTemplate
+		if isRemoteTemplate(templateURL) {
+			nestedValid, nested = validateRemoteNestedTemplate(templateURL, resourceName, templates, resourceSpecification, resourceValidation, ctx)
+		} else {
+			nestedValid, nested = validateLocalNestedTemplate(templateName, templateURL, resourceName, templates, resourceSpecification, resourceValidation, ctx)
+		}
+		valid = nestedValid && valid
+		if nested != nil {
+			nestedTemplates[resourceName] = *nested
+			valid = checkNestedStackParameters(resource, *nested, resourceValidation) && valid
+		}
+	}
+
+	return validateNestedStackOutputs(tmpl, nestedTemplates, ctx.Logger) && valid
+}
+
+func validateLocalNestedTemplate(templateName string, templateURL string, resourceName string, templates []string, resourceSpecification *specification.Specification, resourceValidation *logger.ResourceValidation, ctx *context.Context) (bool, *nestedTemplate) {
+	if isRemoteTemplate(templateName) {
+		resourceValidation.AddWarning("Properties.TemplateURL", "Properties.TemplateURL: Nested template "+templateURL+" is a local path, but "+templateName+" is not a local template, so it is not validated")
+		return true, nil
+	}
+	templatePath := templateURL
+	if !filepath.IsAbs(templatePath) {
+		templatePath = filepath.Join(filepath.Dir(templateName), templateURL)
+	}
+	if _, err := os.Stat(templatePath); err != nil {
+		resourceValidation.AddError("Properties.TemplateURL", "Properties.TemplateURL: Nested template "+templatePath+" does not exist")
+		return false, nil
+	}
+	if includesItself(templatePath, templates, resourceValidation) {
+		return false, nil
+	}
+	return checkNestedTemplate(templatePath, templatePath, resourceName, templates, resourceSpecification, resourceValidation, ctx)
+}
+
+func validateRemoteNestedTemplate(templateURL string, resourceName string, templates []string, resourceSpecification *specification.Specification, resourceValidation *logger.ResourceValidation, ctx *context.Context) (bool, *nestedTemplate) {
+	if ctx.IsOffline() {
+		ctx.Logger.Warning("Offline mode - nested template " + templateURL + " is not validated")
+		return true, nil
+	}
+	if includesItself(templateURL, templates, resourceValidation) {
+		return false, nil
+	}
+	templatePath, err := downloadNestedTemplate(templateURL, ctx)
+	if err != nil {
+		resourceValidation.AddError("Properties.TemplateURL", "Properties.TemplateURL: Could not download nested template "+templateURL+": "+err.Error())
+		return false, nil
+	}
+	defer os.Remove(templatePath)
+	return checkNestedTemplate(templatePath, templateURL, resourceName, templates, resourceSpecification, resourceValidation, ctx)
+}
+
+// includesItself checks if the nested template is one of the templates which include it, directly or through
+// other nested templates.
+func includesItself(templateName string, templates []string, resourceValidation *logger.ResourceValidation) bool {
+	key := getTemplateKey(templateName)
+	for index, parentTemplate := range templates {
+		if parentTemplate == key {
+			cycle := strings.Join(append(append([]string{}, templates[index:]...), key), " -> ")
+			resourceValidation.AddError("Properties.TemplateURL", "Properties.TemplateURL: Nested template "+templateName+" includes itself: "+cycle)
+			return true
+		}
+	}
+	return false
+}
+
+// checkNestedTemplate validates the nested template. Its results are added to the logger of the parent template.
+func checkNestedTemplate(templatePath string, templateName string, resourceName string, templates []string, resourceSpecification *specification.Specification, resourceValidation *logger.ResourceValidation, ctx *context.Context) (bool, *nestedTemplate) {
+	rawTemplate, err := ioutil.ReadFile(templatePath)
+	if err != nil {
+		resourceValidation.AddError("Properties.TemplateURL", "Properties.TemplateURL: Could not read nested template "+templateName+": "+err.Error())
+		return false, nil
+	}
+	parsedTemplate, err := helpers.ParseTemplate(templateName, rawTemplate, ctx.DownloadSnippet, ctx.Logger)
+	if err != nil {
+		resourceValidation.AddError("Properties.TemplateURL", "Properties.TemplateURL: Could not parse nested template "+templateName+": "+err.Error())
+		return false, nil
+	}
+
+	nestedSink := &nestedLogger{LoggerInt: ctx.Logger}
+	nestedContext := *ctx
+	nestedContext.Logger = nestedSink
+	valid := checkTemplateFile(templatePath, templateName, templates, resourceSpecification, &nestedContext)
+	for _, validation := range nestedSink.GetResourceValidations() {
+		validation.ResourceName = resourceName + "/" + validation.ResourceName
+	}
+	ctx.Logger.AppendResourceValidations(nestedSink.GetResourceValidations())
+	if !valid {
+		resourceValidation.AddError("Properties.TemplateURL", "Properties.TemplateURL: Nested template "+templateName+" is invalid")
+	}
+	return valid, &nestedTemplate{name: templateName, template: parsedTemplate}
+}
+
+// checkNestedStackParameters checks if all parameters without default values are passed to the nested stack
+// and if all passed parameters are declared in the nested template.
+func checkNestedStackParameters(resource template.Resource, nested nestedTemplate, resourceValidation *logger.ResourceValidation) bool {
+	parameters := make(map[string]interface{})
+	if rawParameters, ok := resource.Properties["Parameters"]; ok {
+		var isMap bool
+		parameters, isMap = rawParameters.(map[string]interface{})
+		if _, isFunction := isIntrinsicFunction(rawParameters); !isMap || isFunction {
+			return true
+		}
+	}
+
+	valid := true
+	for _, name := range sortedKeys(nested.template.Parameters) {
+		if _, passed := parameters[name]; passed {
+			continue
+		}
+		if parameter, ok := nested.template.Parameters[name].(map[string]interface{}); ok {
+			if _, hasDefault := parameter["Default"]; hasDefault {
+				continue
+			}
+		}
+		resourceValidation.AddError("Properties.Parameters", "Properties.Parameters: Parameter "+name+" of nested template "+nested.name+" has no default value, so it has to be passed")
+		valid = false
+	}
+	for _, name := range sortedKeys(parameters) {
+		if _, declared := nested.template.Parameters[name]; !declared {
+			resourceValidation.AddError("Properties.Parameters."+name, "Properties.Parameters."+name+": Parameter "+name+" is not declared in nested template "+nested.name+suggestion(name, sortedKeys(nested.template.Parameters)))
+			valid = false
+		}
+	}
+	return valid
+}
+
+// validateNestedStackOutputs checks if outputs of nested stacks used with Fn::GetAtt and Fn::Sub
+// (<nested stack>.Outputs.<output>) are declared in their templates.
+func validateNestedStackOutputs(tmpl template.Template, nestedTemplates map[string]nestedTemplate, sink logger.LoggerInt) bool {
+	valid := true
+	checkOutputs := func(references []reference, elementName string) {
+		for _, ref := range references {
+			nested, isNested := nestedTemplates[ref.Target]
+			if !isNested || !strings.HasPrefix(ref.Attribute, "Outputs.") {
+				continue
+			}
+			outputName := strings.TrimPrefix(ref.Attribute, "Outputs.")
+			if _, declared := nested.template.Outputs[outputName]; !declared {
+				sink.AddResourceForValidation(elementName).AddError(ref.Path, ref.Path+": "+ref.Function+" to output "+outputName+
+					" which is not declared in nested template "+nested.name+suggestion(outputName, sortedKeys(nested.template.Outputs)))
+				valid = false
+			}
+		}
+	}
+	for _, resourceName := range sortedResourceNames(tmpl.Resources) {
+		checkOutputs(findReferences(tmpl.Resources[resourceName].Properties, "Properties"), resourceName)
+	}
+	for _, outputName := range sortedKeys(tmpl.Outputs) {
+		checkOutputs(findReferences(tmpl.Outputs[outputName], outputName), "Outputs")
+	}
+	return valid
+}
+
+func downloadNestedTemplate(templateURL string, ctx *context.Context) (string, error) {
+	tempfile, err := ioutil.TempFile(ctx.Config.DefaultTemporaryFilesDirectory, "")
+	if err != nil {
+		return "", err
+	}
+	defer tempfile.Close()
+
+	if err := downloadTemplateFromBucket(templateURL, tempfile, ctx); err != nil {
+		os.Remove(tempfile.Name())
+		return "", err
+	}
+	return tempfile.Name(), nil
+}
+
+// NewSnippetDownloader creates function which downloads snippets included from S3 with AWS::Include transform.
+func NewSnippetDownloader(ctx *context.Context) helpers.SnippetDownloader {
+	return func(location string) ([]byte, error) {
+		if ctx.IsOffline() {
+			return nil, errors.New("Offline mode - snippet stored in S3 can't be downloaded")
+		}
+		tempPath, err := downloadNestedTemplate(location, ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(tempPath)
+		return ioutil.ReadFile(tempPath)
+	}
+}
+
+func isRemoteTemplate(templateURL string) bool {
+	return strings.Contains(templateURL, "://")
+}
+
+// getTemplateKey identifies the template in a chain of nested templates.
+func getTemplateKey(templateName string) string {
+	if isRemoteTemplate(templateName) {
+		return templateName
+	}
+	if absolutePath, err := filepath.Abs(templateName); err == nil {
+		return absolutePath
+	}
+	return templateName
+}
@@ -0,0 +1,197 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Appliscale/perun/cliparser"
+	"github.com/Appliscale/perun/context"
+	"github.com/Appliscale/perun/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func checkNestedTemplates(t *testing.T, templates map[string]string) (bool, *logger.Logger) {
+	directory, err := ioutil.TempDir("", "perun-nested")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	for name, body := range templates {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(directory, name)), 0755))
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(directory, name), []byte(body), 0644))
+	}
+
+	offline := true
+	sink := &logger.Logger{Output: &bytes.Buffer{}}
+	ctx := &context.Context{CliArguments: cliparser.CliArguments{Offline: &offline}, Logger: sink}
+	templatePath := filepath.Join(directory, "parent.yaml")
+	return checkTemplateFile(templatePath, templatePath, nil, &spec, ctx), sink
+}
+
+func getValidationErrors(sink *logger.Logger, resourceName string) []string {
+	for _, validation := range sink.GetResourceValidations() {
+		if validation.ResourceName == resourceName {
+			return validation.Errors
+		}
+	}
+	return nil
+}
+
+const childTemplate = `Parameters:
+  Name:
+    Type: String
+  Size:
+    Type: Number
+    Default: 1
+Resources:
+  Example:
+    Type: ExampleResourceType
+    Properties:
+      ExampleProperty: !Ref Name
+Outputs:
+  ExampleName:
+    Value: !Ref Example
+`
+
+func TestValidLocalNestedStack(t *testing.T) {
+	valid, sink := checkNestedTemplates(t, map[string]string{
+		"parent.yaml": `Resources:
+  Child:
+    Type: AWS::CloudFormation::Stack
+    Properties:
+      TemplateURL: nested/child.yaml
+      Parameters:
+        Name: example
+  Example:
+    Type: ExampleResourceType
+    Properties:
+      ExampleProperty: !GetAtt Child.Outputs.ExampleName
+`,
+		"nested/child.yaml": childTemplate,
+	})
+	assert.True(t, valid)
+	assert.False(t, sink.HasValidationErrors())
+}
+
+func TestNestedStackParameters(t *testing.T) {
+	valid, sink := checkNestedTemplates(t, map[string]string{
+		"parent.yaml": `Resources:
+  Child:
+    Type: AWS::CloudFormation::Stack
+    Properties:
+      TemplateURL: child.yaml
+      Parameters:
+        Nme: example
+`,
+		"child.yaml": childTemplate,
+	})
+	assert.False(t, valid)
+	errors := getValidationErrors(sink, "Child")
+	assert.Len(t, errors, 2)
+	assert.Contains(t, errors[0], "Properties.Parameters: Parameter Name of nested template")
+	assert.Contains(t, errors[1], "Properties.Parameters.Nme: Parameter Nme is not declared in nested template")
+	assert.Contains(t, errors[1], "Did you mean Name?")
+}
+
+func TestNestedStackOutputs(t *testing.T) {
+	valid, sink := checkNestedTemplates(t, map[string]string{
+		"parent.yaml": `Resources:
+  Child:
+    Type: AWS::CloudFormation::Stack
+    Properties:
+      TemplateURL: child.yaml
+      Parameters:
+        Name: example
+Outputs:
+  ChildName:
+    Value: !Sub "${Child.Outputs.Name}"
+`,
+		"child.yaml": childTemplate,
+	})
+	assert.False(t, valid)
+	errors := getValidationErrors(sink, "Outputs")
+	assert.Len(t, errors, 1)
+	assert.Contains(t, errors[0], "ChildName.Value: Fn::Sub to output Name which is not declared in nested template")
+}
+
+func TestInvalidNestedTemplate(t *testing.T) {
+	valid, sink := checkNestedTemplates(t, map[string]string{
+		"parent.yaml": `Resources:
+  Child:
+    Type: AWS::CloudFormation::Stack
+    Properties:
+      TemplateURL: child.yaml
+`,
+		"child.yaml": invalidTemplate,
+	})
+	assert.False(t, valid)
+	assert.Contains(t, getValidationErrors(sink, "Child")[0], "child.yaml is invalid")
+	assert.Equal(t, []string{"Property ExampleProperty is required"}, getValidationErrors(sink, "Child/Example"))
+}
+
+func TestMissingNestedTemplate(t *testing.T) {
+	valid, sink := checkNestedTemplates(t, map[string]string{
+		"parent.yaml": `Resources:
+  Child:
+    Type: AWS::CloudFormation::Stack
+    Properties:
+      TemplateURL: child.yaml
+`,
+	})
+	assert.False(t, valid)
+	assert.Contains(t, getValidationErrors(sink, "Child")[0], "child.yaml does not exist")
+}
+
+func TestNestedStackCycle(t *testing.T) {
+	valid, sink := checkNestedTemplates(t, map[string]string{
+		"parent.yaml": `Resources:
+  Child:
+    Type: AWS::CloudFormation::Stack
+    Properties:
+      TemplateURL: child.yaml
+`,
+		"child.yaml": `Resources:
+  Parent:
+    Type: AWS::CloudFormation::Stack
+    Properties:
+      TemplateURL: parent.yaml
+`,
+	})
+	assert.False(t, valid)
+	errors := getValidationErrors(sink, "Child/Parent")
+	assert.Len(t, errors, 1)
+	assert.Regexp(t, "includes itself: .*parent.yaml -> .*child.yaml -> .*parent.yaml", errors[0])
+}
+
+func TestFetchBucketDataFromURL(t *testing.T) {
+	region, bucket, key, err := fetchBucketDataFromURL("https://s3.eu-west-1.amazonaws.com/bucket/nested/template.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"eu-west-1", "bucket", "nested/template.yaml"}, []string{region, bucket, key})
+
+	region, bucket, key, err = fetchBucketDataFromURL("s3://bucket/nested/template.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"", "bucket", "nested/template.yaml"}, []string{region, bucket, key})
+
+	_, _, _, err = fetchBucketDataFromURL("https://example.com")
+	assert.NotNil(t, err)
+
+	_, _, _, err = fetchBucketDataFromURL("s3://bucket")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,237 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Appliscale/perun/helpers"
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+)
+
+var basicParameterTypes = []string{
+	"String",
+	"Number",
+	"List<Number>",
+	"CommaDelimitedList",
+}
+
+// AWS-specific parameter types, which can also be used as List<...> and inside AWS::SSM::Parameter::Value<...>.
+var awsParameterTypes = []string{
+	"AWS::EC2::AvailabilityZone::Name",
+	"AWS::EC2::Image::Id",
+	"AWS::EC2::Instance::Id",
+	"AWS::EC2::KeyPair::KeyName",
+	"AWS::EC2::SecurityGroup::GroupName",
+	"AWS::EC2::SecurityGroup::Id",
+	"AWS::EC2::Subnet::Id",
+	"AWS::EC2::Volume::Id",
+	"AWS::EC2::VPC::Id",
+	"AWS::Route53::HostedZone::Id",
+}
+
+var ssmParameterValueRegex = regexp.MustCompile(`^AWS::SSM::Parameter::Value<(.+)>$`)
+var listParameterRegex = regexp.MustCompile(`^List<(.+)>$`)
+
+// isValidParameterType checks if the type is one of types supported by CloudFormation parameters.
+func isValidParameterType(parameterType string) bool {
+	if helpers.SliceContains(basicParameterTypes, parameterType) || helpers.SliceContains(awsParameterTypes, parameterType) {
+		return true
+	}
+	if parameterType == "AWS::SSM::Parameter::Name" {
+		return true
+	}
+	if match := listParameterRegex.FindStringSubmatch(parameterType); match != nil {
+		return helpers.SliceContains(awsParameterTypes, match[1])
+	}
+	if match := ssmParameterValueRegex.FindStringSubmatch(parameterType); match != nil {
+		valueType := match[1]
+		if valueType == "String" || valueType == "List<String>" || valueType == "CommaDelimitedList" {
+			return true
+		}
+		if list := listParameterRegex.FindStringSubmatch(valueType); list != nil {
+			valueType = list[1]
+		}
+		return helpers.SliceContains(awsParameterTypes, valueType)
+	}
+	return false
+}
+
+func isNumberParameterType(parameterType string) bool {
+	return parameterType == "Number" || parameterType == "List<Number>"
+}
+
+func isListParameterType(parameterType string) bool {
+	return parameterType == "CommaDelimitedList" || listParameterRegex.MatchString(parameterType)
+}
+
+// validateParameters checks parameter types, constraints and whether default values satisfy them.
+func validateParameters(parameters template.Parameters, sink logger.LoggerInt) bool {
+	valid := true
+	parametersValidation := sink.AddResourceForValidation("Parameters")
+	for _, parameterName := range sortedKeys(parameters) {
+		parameter, ok := parameters[parameterName].(map[string]interface{})
+		if !ok {
+			parametersValidation.AddError(parameterName, parameterName+": Parameter has to be an object")
+			valid = false
+			continue
+		}
+		errors, warnings := checkParameter(parameter)
+		for _, message := range errors {
+			parametersValidation.AddError(parameterName, parameterName+": "+message)
+			valid = false
+		}
+		for _, message := range warnings {
+			parametersValidation.AddWarning(parameterName, parameterName+": "+message)
+		}
+	}
+	return valid
+}
+
+// checkParameter returns errors and warnings about the parameter definition. AllowedPattern which can't be compiled
+// (e.g. it uses syntax not supported by Go regular expressions) is reported as a warning and Default value is not
+// checked against it.
+func checkParameter(parameter map[string]interface{}) (errors []string, warnings []string) {
+	parameterType, ok := parameter["Type"].(string)
+	if !ok {
+		return []string{"Type needs to be specified"}, nil
+	}
+	if !isValidParameterType(parameterType) {
+		return []string{"Type " + parameterType + " is not a valid parameter type"}, nil
+	}
+
+	for _, constraint := range []string{"MinValue", "MaxValue"} {
+		if _, hasConstraint := parameter[constraint]; hasConstraint && !isNumberParameterType(parameterType) {
+			errors = append(errors, constraint+" can be used only with Number type")
+		}
+	}
+	constraints := []string{"MinLength", "MaxLength", "MinValue", "MaxValue"}
+	for _, constraint := range constraints {
+		if value, hasConstraint := parameter[constraint]; hasConstraint {
+			if _, isNumber := toNumber(value); !isNumber {
+				errors = append(errors, constraint+" must be a number")
+			}
+		}
+	}
+	var pattern *regexp.Regexp
+	if allowedPattern, hasPattern := parameter["AllowedPattern"]; hasPattern {
+		patternString, isString := allowedPattern.(string)
+		if !isString {
+			errors = append(errors, "AllowedPattern has to be a string")
+		} else if compiled, err := regexp.Compile("^(?:" + patternString + ")$"); err != nil {
+			warnings = append(warnings, "AllowedPattern "+patternString+" could not be checked, so Default value is not validated against it: "+err.Error())
+		} else {
+			pattern = compiled
+		}
+	}
+	if len(errors) > 0 {
+		return
+	}
+
+	defaultValue, hasDefault := parameter["Default"]
+	if !hasDefault {
+		return
+	}
+	values := []string{parameterValueToString(defaultValue)}
+	if isListParameterType(parameterType) {
+		values = strings.Split(values[0], ",")
+		for index := range values {
+			values[index] = strings.TrimSpace(values[index])
+		}
+	}
+	for _, value := range values {
+		errors = append(errors, checkDefaultValue(parameter, parameterType, value, pattern)...)
+	}
+	return
+}
+
+func checkDefaultValue(parameter map[string]interface{}, parameterType string, value string, pattern *regexp.Regexp) (errors []string) {
+	if allowedValues, ok := parameter["AllowedValues"].([]interface{}); ok {
+		allowed := make([]string, 0, len(allowedValues))
+		for _, allowedValue := range allowedValues {
+			allowed = append(allowed, parameterValueToString(allowedValue))
+		}
+		if !helpers.SliceContains(allowed, value) {
+			errors = append(errors, "Default value "+value+" is not one of AllowedValues: "+strings.Join(allowed, ", "))
+		}
+	}
+	if pattern != nil && !pattern.MatchString(value) {
+		errors = append(errors, "Default value "+value+" doesn't match AllowedPattern "+parameter["AllowedPattern"].(string))
+	}
+	if parameterType == "String" {
+		if minLength, ok := toNumber(parameter["MinLength"]); ok && float64(len(value)) < minLength {
+			errors = append(errors, "Default value "+value+" is shorter than MinLength "+formatNumber(minLength))
+		}
+		if maxLength, ok := toNumber(parameter["MaxLength"]); ok && float64(len(value)) > maxLength {
+			errors = append(errors, "Default value "+value+" is longer than MaxLength "+formatNumber(maxLength))
+		}
+	}
+	if isNumberParameterType(parameterType) {
+		number, isNumber := toNumber(value)
+		if !isNumber {
+			return append(errors, "Default value "+value+" is not a number")
+		}
+		if minValue, ok := toNumber(parameter["MinValue"]); ok && number < minValue {
+			errors = append(errors, "Default value "+value+" is lower than MinValue "+formatNumber(minValue))
+		}
+		if maxValue, ok := toNumber(parameter["MaxValue"]); ok && number > maxValue {
+			errors = append(errors, "Default value "+value+" is greater than MaxValue "+formatNumber(maxValue))
+		}
+	}
+	return
+}
+
+// toNumber converts numbers and numeric strings (templates allow both) to float64.
+func toNumber(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return number, true
+	case int:
+		return float64(number), true
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+		return parsed, err == nil
+	}
+	return 0, false
+}
+
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+func parameterValueToString(value interface{}) string {
+	switch typed := value.(type) {
+	case string:
+		return typed
+	case float64:
+		return formatNumber(typed)
+	case int:
+		return strconv.Itoa(typed)
+	case bool:
+		return strconv.FormatBool(typed)
+	case []interface{}:
+		items := make([]string, 0, len(typed))
+		for _, item := range typed {
+			items = append(items, parameterValueToString(item))
+		}
+		return strings.Join(items, ",")
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"testing"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidParameterType(t *testing.T) {
+	validTypes := []string{
+		"String",
+		"Number",
+		"List<Number>",
+		"CommaDelimitedList",
+		"AWS::EC2::VPC::Id",
+		"List<AWS::EC2::Subnet::Id>",
+		"AWS::SSM::Parameter::Name",
+		"AWS::SSM::Parameter::Value<String>",
+		"AWS::SSM::Parameter::Value<List<String>>",
+		"AWS::SSM::Parameter::Value<AWS::EC2::Image::Id>",
+		"AWS::SSM::Parameter::Value<List<AWS::EC2::SecurityGroup::Id>>",
+	}
+	for _, parameterType := range validTypes {
+		assert.True(t, isValidParameterType(parameterType), parameterType+" should be valid")
+	}
+
+	invalidTypes := []string{"Integer", "List<String>", "AWS::EC2::Vpc::Id", "List<AWS::S3::Bucket>", "AWS::SSM::Parameter::Value<Number>"}
+	for _, parameterType := range invalidTypes {
+		assert.False(t, isValidParameterType(parameterType), parameterType+" should be invalid")
+	}
+}
+
+func TestValidParameters(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Parameters:
+  Environment:
+    Type: String
+    Default: dev
+    AllowedValues: [dev, prod]
+  Name:
+    Type: String
+    Default: perun-bucket
+    AllowedPattern: "[a-z-]+"
+    MinLength: 3
+    MaxLength: "63"
+  Size:
+    Type: Number
+    Default: 20
+    MinValue: 1
+    MaxValue: 100
+  Ports:
+    Type: List<Number>
+    Default: "80, 443"
+    MaxValue: 65535
+  Subnets:
+    Type: List<AWS::EC2::Subnet::Id>
+`)
+
+	assert.True(t, validateParameters(tmpl.Parameters, &sink))
+}
+
+func TestInvalidParameterType(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	parameters := createParameters("Vpc", map[string]interface{}{"Type": "AWS::EC2::Vpc"})
+
+	assert.False(t, validateParameters(parameters, &sink))
+	assert.Equal(t, []string{"Vpc: Type AWS::EC2::Vpc is not a valid parameter type"},
+		sink.AddResourceForValidation("Parameters").Errors)
+}
+
+func TestDefaultValueViolatingConstraints(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Parameters:
+  Environment:
+    Type: String
+    Default: test
+    AllowedValues: [dev, prod]
+  Name:
+    Type: String
+    Default: Perun
+    AllowedPattern: "[a-z]+"
+    MaxLength: 4
+  Size:
+    Type: Number
+    Default: 200
+    MaxValue: 100
+  Ports:
+    Type: List<Number>
+    Default: "80,http"
+`)
+
+	assert.False(t, validateParameters(tmpl.Parameters, &sink))
+	assert.Equal(t, []string{
+		"Environment: Default value test is not one of AllowedValues: dev, prod",
+		"Name: Default value Perun doesn't match AllowedPattern [a-z]+",
+		"Name: Default value Perun is longer than MaxLength 4",
+		"Ports: Default value http is not a number",
+		"Size: Default value 200 is greater than MaxValue 100",
+	}, sink.AddResourceForValidation("Parameters").Errors)
+}
+
+func TestNumericConstraintOnStringParameter(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	parameters := createParameters("Name", map[string]interface{}{"Type": "String", "MinValue": 1.0})
+
+	assert.False(t, validateParameters(parameters, &sink))
+	assert.Equal(t, []string{"Name: MinValue can be used only with Number type"},
+		sink.AddResourceForValidation("Parameters").Errors)
+}
+
+func TestAllowedPatternNotSupportedByGo(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	parameters := createParameters("Name", map[string]interface{}{
+		"Type":           "String",
+		"Default":        "perun",
+		"AllowedPattern": "(?!admin)[a-z]+",
+	})
+
+	assert.True(t, validateParameters(parameters, &sink))
+	warnings := sink.AddResourceForValidation("Parameters").Warnings
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "Name: AllowedPattern (?!admin)[a-z]+ could not be checked, so Default value is not validated against it")
+}
+
+func TestLengthConstraintsOnlyForStringParameters(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	parameters := createParameters("Zones", map[string]interface{}{
+		"Type":      "CommaDelimitedList",
+		"Default":   "eu-west-1a,eu-west-1b",
+		"MaxLength": 5.0,
+	})
+
+	assert.True(t, validateParameters(parameters, &sink))
+}
@@ -0,0 +1,148 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parsers
+
+import (
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Appliscale/perun/validator/template"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseYaml parses byte to TemplateWithDetails. Short forms of intrinsic functions (e.g. !Ref) are represented
+// like their full forms, so both JSON and YAML templates have the same structure.
+func ParseYaml(fileContents []byte, tmpl *template.TemplateWithDetails) error {
+	var document yaml.Node
+	if err := yaml.Unmarshal(fileContents, &document); err != nil {
+		return err
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 {
+		return errors.New("Template is empty")
+	}
+	root := yamlNodeToElement("", document.Content[0])
+	if root.Type != template.Object {
+		return errors.New("Template has to be an object")
+	}
+	elements := root.GetChildrenMap()
+	*tmpl = template.TemplateWithDetails{
+		AWSTemplateFormatVersion: elements["AWSTemplateFormatVersion"],
+		Description:              elements["Description"],
+		Metadata:                 elements["Metadata"],
+		Parameters:               elements["Parameters"],
+		Mappings:                 elements["Mappings"],
+		Conditions:               elements["Conditions"],
+		Transform:                elements["Transform"],
+		Resources:                elements["Resources"],
+		Outputs:                  elements["Outputs"]}
+	return nil
+}
+
+// ParseWithDetails chooses parser based on file extension and parses byte to TemplateWithDetails.
+func ParseWithDetails(fileName string, fileContents []byte, tmpl *template.TemplateWithDetails) error {
+	switch path.Ext(fileName) {
+	case ".json":
+		return ParseJson(fileContents, tmpl)
+	case ".yaml", ".yml":
+		return ParseYaml(fileContents, tmpl)
+	default:
+		return errors.New("Invalid template file format.")
+	}
+}
+
+func yamlNodeToElement(name string, node *yaml.Node) *template.TemplateElement {
+	element := &template.TemplateElement{
+		Name:   name,
+		Line:   node.Line,
+		Column: node.Column,
+	}
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	if function, isShortForm := shortFormFunctionName(node.Tag); isShortForm {
+		tagless := *node
+		tagless.Tag = ""
+		if node.Kind == yaml.ScalarNode {
+			tagless.Tag = "!!str"
+		}
+		element.Type = template.Object
+		element.Children = map[string]*template.TemplateElement{function: yamlNodeToElement(function, &tagless)}
+		return element
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		children := make(map[string]*template.TemplateElement)
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			key := node.Content[index]
+			child := yamlNodeToElement(key.Value, node.Content[index+1])
+			child.Line, child.Column = key.Line, key.Column
+			children[key.Value] = child
+		}
+		element.Type = template.Object
+		element.Children = children
+	case yaml.SequenceNode:
+		children := make([]*template.TemplateElement, 0, len(node.Content))
+		for index, item := range node.Content {
+			children = append(children, yamlNodeToElement("["+strconv.Itoa(index)+"]", item))
+		}
+		element.Type = template.Array
+		element.Children = &children
+	case yaml.ScalarNode:
+		element.Type, element.Value = yamlScalarValue(node)
+	default:
+		element.Type = template.Unknown
+	}
+	return element
+}
+
+// shortFormFunctionName translates YAML tag of a short form function to the full function name, e.g. !Sub to Fn::Sub.
+func shortFormFunctionName(tag string) (string, bool) {
+	if !strings.HasPrefix(tag, "!") || strings.HasPrefix(tag, "!!") {
+		return "", false
+	}
+	name := strings.TrimPrefix(tag, "!")
+	if name == "Ref" || name == "Condition" {
+		return name, true
+	}
+	return "Fn::" + name, true
+}
+
+func yamlScalarValue(node *yaml.Node) (template.TemplateElementValueType, interface{}) {
+	switch node.ShortTag() {
+	case "!!int":
+		var value int64
+		if node.Decode(&value) == nil {
+			return template.Number, value
+		}
+	case "!!float":
+		var value float64
+		if node.Decode(&value) == nil {
+			return template.Number, value
+		}
+	case "!!bool":
+		var value bool
+		if node.Decode(&value) == nil {
+			return template.Boolean, value
+		}
+	case "!!null":
+		return template.Null, nil
+	}
+	return template.String, node.Value
+}
@@ -0,0 +1,62 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parsers
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/Appliscale/perun/validator/template"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYamlParser(t *testing.T) {
+	fileName := "test_resources/sample_template.yaml"
+	fileContents, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatal("Error while loading file: "+fileName, err)
+	}
+	tmpl := template.TemplateWithDetails{}
+	err = ParseWithDetails(fileName, fileContents, &tmpl)
+	if err != nil {
+		t.Fatal("Error while parsing file: ", err)
+	}
+	element := tmpl.Mappings.GetChildrenMap()["RegionMap"].GetChildrenMap()["eu-west-1"].GetChildrenMap()["32"]
+	assert.NotNil(t, element)
+	assert.Equal(t, template.String, element.Type)
+	assert.Equal(t, "32", element.Name)
+	assert.Equal(t, "ami-37c2f643", element.Value)
+	assert.Equal(t, 5, element.Line)
+	assert.Equal(t, 7, element.Column)
+	assert.Nil(t, element.Children)
+
+	properties := tmpl.Resources.GetChildrenMap()["myEC2Instance"].GetChildrenMap()["Properties"].GetChildrenMap()
+	element = properties["ImageId"].GetChildrenMap()["Fn::FindInMap"].GetChildrenSlice()[1]
+	assert.Equal(t, template.Object, element.Type)
+	assert.Equal(t, "AWS::Region", element.GetChildrenMap()["Ref"].Value)
+	assert.Equal(t, 12, element.Line)
+	assert.Equal(t, 39, element.Column)
+
+	assert.Equal(t, template.Boolean, properties["Monitoring"].Type)
+	assert.Equal(t, true, properties["Monitoring"].Value)
+	assert.Equal(t, template.Null, properties["EbsOptimized"].Type)
+
+	element = properties["Tags"].GetChildrenSlice()[0].GetChildrenMap()["Value"].GetChildrenMap()["Fn::Sub"]
+	assert.Equal(t, template.String, element.Type)
+	assert.Equal(t, "${AWS::StackName}-instance", element.Value)
+	assert.Equal(t, 18, element.Line)
+}
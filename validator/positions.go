@@ -0,0 +1,103 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"regexp"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+)
+
+var pathElementRegex = regexp.MustCompile(`[^.\[\]]+|\[\d+\]`)
+
+// pathError is a validation error of the element under path, relative to the validated resource or section.
+type pathError struct {
+	path    string
+	message string
+}
+
+func (e pathError) String() string {
+	if e.path == "" {
+		return e.message
+	}
+	return e.path + ": " + e.message
+}
+
+// locateValidationMessages sets file, line and column of every validation error and warning. The position is found
+// using the name of validated element and the path recorded with the message, e.g. Properties.Name.
+func locateValidationMessages(details template.TemplateWithDetails, fileName string, sink logger.LoggerInt) {
+	for _, resourceValidation := range sink.GetResourceValidations() {
+		element := findValidatedElement(details, resourceValidation.ResourceName)
+		resourceValidation.Locate(func(path string) logger.Position {
+			position := logger.Position{File: fileName}
+			if found := findPathElement(element, path); found != nil {
+				position.Line, position.Column = found.Line, found.Column
+			}
+			return position
+		})
+	}
+}
+
+// findValidatedElement finds template section or resource which the validation messages were added for.
+func findValidatedElement(details template.TemplateWithDetails, name string) *template.TemplateElement {
+	sections := map[string]*template.TemplateElement{
+		"Parameters": details.Parameters,
+		"Mappings":   details.Mappings,
+		"Conditions": details.Conditions,
+		"Transform":  details.Transform,
+		"Resources":  details.Resources,
+		"Outputs":    details.Outputs,
+	}
+	if section, isSection := sections[name]; isSection {
+		return section
+	}
+	if details.Resources != nil && details.Resources.Type == template.Object {
+		return details.Resources.GetChildrenMap()[name]
+	}
+	return nil
+}
+
+// findPathElement walks down the path from the validated element as far as possible and returns the most nested
+// element found. It returns the validated element if the path doesn't point at any of its children.
+func findPathElement(element *template.TemplateElement, path string) *template.TemplateElement {
+	if element == nil {
+		return nil
+	}
+	for _, name := range pathElementRegex.FindAllString(path, -1) {
+		child := findChild(element, name)
+		if child == nil {
+			break
+		}
+		element = child
+	}
+	return element
+}
+
+func findChild(element *template.TemplateElement, name string) *template.TemplateElement {
+	switch element.Type {
+	case template.Object:
+		return element.GetChildrenMap()[name]
+	case template.Array:
+		for _, child := range element.GetChildrenSlice() {
+			if child != nil && child.Name == name {
+				return child
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"testing"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/parsers"
+	"github.com/Appliscale/perun/validator/template"
+	"github.com/stretchr/testify/assert"
+)
+
+const positionsTemplate = `Parameters:
+  Environment:
+    Type: String
+Resources:
+  Function:
+    Type: AWS::Primitive1::Function
+    Properties:
+      MemorySize: !Ref Memroy
+      Ports:
+        - 80
+        - http
+`
+
+func TestLocateValidationMessages(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	details := template.TemplateWithDetails{}
+	err := parsers.ParseWithDetails("template.yaml", []byte(positionsTemplate), &details)
+	if err != nil {
+		t.Fatal("Error while parsing template: ", err)
+	}
+
+	function := sink.AddResourceForValidation("Function")
+	function.AddError("Properties.MemorySize", "Properties.MemorySize: Ref to undefined parameter or resource Memroy")
+	function.AddError("Properties.Ports[1]", "Property Ports[1] must be of type Integer, but the value is: http")
+	function.AddValidationWarning("Some warning about the resource")
+	sink.AddResourceForValidation("Parameters").AddError("Environment", "Environment: Default value is missing")
+	sink.AddResourceForValidation("Template").AddValidationError("Template body is too large")
+
+	locateValidationMessages(details, "template.yaml", &sink)
+
+	assert.Equal(t, "template.yaml:8:7", function.ErrorPositions[0].String())
+	assert.Equal(t, "template.yaml:11:11", function.ErrorPositions[1].String())
+	assert.Equal(t, "template.yaml:5:3", function.WarningPositions[0].String())
+	assert.Equal(t, "template.yaml:2:3", sink.AddResourceForValidation("Parameters").ErrorPositions[0].String())
+	assert.Equal(t, "template.yaml", sink.AddResourceForValidation("Template").ErrorPositions[0].String())
+}
+
+func TestLocateValidationMessagesInJson(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	details := template.TemplateWithDetails{}
+	err := parsers.ParseWithDetails("template.json", []byte(`{
+  "Resources": {
+    "Function": {
+      "Type": "AWS::Primitive1::Function",
+      "Properties": { "MemorySize": "big" }
+    }
+  }
+}`), &details)
+	if err != nil {
+		t.Fatal("Error while parsing template: ", err)
+	}
+
+	function := sink.AddResourceForValidation("Function")
+	function.AddError("Properties.MemorySize", "Property MemorySize must be of type Integer, but the value is: big")
+
+	locateValidationMessages(details, "template.json", &sink)
+
+	position := function.ErrorPositions[0]
+	assert.Equal(t, "template.json", position.File)
+	assert.Equal(t, 5, position.Line)
+}
+
+func TestLocateValidationMessagesFromValidators(t *testing.T) {
+	const mappingsTemplate = `Mappings:
+  RegionMap:
+    us-east-1:
+      AMI: {Name: ami-1}
+`
+	sink := logger.CreateQuietLogger()
+	details := template.TemplateWithDetails{}
+	err := parsers.ParseWithDetails("template.yaml", []byte(mappingsTemplate), &details)
+	if err != nil {
+		t.Fatal("Error while parsing template: ", err)
+	}
+
+	validateMappings(parseTestTemplate(t, mappingsTemplate), "us-east-1", nil, &sink)
+	locateValidationMessages(details, "template.yaml", &sink)
+
+	mappings := sink.AddResourceForValidation("Mappings")
+	assert.Equal(t, []string{"RegionMap.us-east-1.AMI"}, mappings.ErrorPaths)
+	assert.Equal(t, "template.yaml:4:7", mappings.ErrorPositions[0].String())
+}
@@ -0,0 +1,269 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Appliscale/perun/logger"
+)
+
+// Primitive types used in the Resource Specification.
+const (
+	primitiveString    = "String"
+	primitiveInteger   = "Integer"
+	primitiveLong      = "Long"
+	primitiveDouble    = "Double"
+	primitiveBoolean   = "Boolean"
+	primitiveTimestamp = "Timestamp"
+	primitiveJson      = "Json"
+)
+
+// Kinds of values which intrinsic functions evaluate to.
+type intrinsicResult int
+
+const (
+	unknownResult intrinsicResult = iota
+	scalarResult
+	listResult
+)
+
+// Intrinsic functions with a result kind known before the template is deployed.
+var intrinsicResults = map[string]intrinsicResult{
+	"Fn::Base64": scalarResult,
+	"Fn::Join":   scalarResult,
+	"Fn::Sub":    scalarResult,
+	"Fn::GetAZs": listResult,
+	"Fn::Split":  listResult,
+	"Fn::Cidr":   listResult,
+}
+
+// isIntrinsicFunction checks if value is a call of an intrinsic function, e.g. {"Ref": "Parameter"}.
+func isIntrinsicFunction(value interface{}) (name string, ok bool) {
+	function, isMap := value.(map[string]interface{})
+	if !isMap || len(function) != 1 {
+		return "", false
+	}
+	for key := range function {
+		if key == "Ref" || key == "Condition" || strings.HasPrefix(key, "Fn::") {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// checkPrimitiveProperty checks if the property value matches the primitive type from the specification.
+// Path is the path of the properties map inside the resource.
+func checkPrimitiveProperty(
+	resourceProperties map[string]interface{},
+	path, propertyName, primitiveType string,
+	resourceValidation *logger.ResourceValidation) {
+
+	checkPrimitiveValue(path+"."+propertyName, propertyName, resourceProperties[propertyName], primitiveType, resourceValidation)
+}
+
+// checkPrimitiveItems checks if every item of a List or Map property matches the primitive item type.
+func checkPrimitiveItems(
+	resourceProperties map[string]interface{},
+	path, propertyName, primitiveItemType string,
+	resourceValidation *logger.ResourceValidation) {
+
+	if primitiveItemType == "" {
+		return
+	}
+	switch items := resourceProperties[propertyName].(type) {
+	case []interface{}:
+		for index, item := range items {
+			itemName := propertyName + "[" + strconv.Itoa(index) + "]"
+			checkPrimitiveValue(path+"."+itemName, itemName, item, primitiveItemType, resourceValidation)
+		}
+	case map[string]interface{}:
+		if _, isFunction := isIntrinsicFunction(items); isFunction {
+			return
+		}
+		for key, item := range items {
+			itemName := propertyName + "." + key
+			checkPrimitiveValue(path+"."+itemName, itemName, item, primitiveItemType, resourceValidation)
+		}
+	}
+}
+
+// checkListValue checks if the value can be used where the specification expects a List.
+// Path is the path of the value inside the resource.
+func checkListValue(path string, propertyName string, value interface{}, resourceValidation *logger.ResourceValidation) bool {
+	switch value.(type) {
+	case nil, []interface{}:
+		return true
+	}
+	if function, isFunction := isIntrinsicFunction(value); isFunction {
+		if function == "Fn::If" {
+			return checkConditionalBranches(value, func(branch interface{}) bool {
+				return checkListValue(path, propertyName, branch, resourceValidation)
+			})
+		}
+		if intrinsicResults[function] != scalarResult {
+			return true
+		}
+		resourceValidation.AddError(path, propertyName+" must be a List, but "+function+" returns a String")
+		return false
+	}
+	resourceValidation.AddError(path, propertyName+" must be a List")
+	return false
+}
+
+func checkPrimitiveValue(path string, propertyName string, value interface{}, primitiveType string, resourceValidation *logger.ResourceValidation) bool {
+	if value == nil {
+		return true
+	}
+	if function, isFunction := isIntrinsicFunction(value); isFunction {
+		return checkIntrinsicResult(path, propertyName, value, function, primitiveType, resourceValidation)
+	}
+
+	valid := true
+	switch primitiveType {
+	case primitiveString:
+		valid = isScalar(value)
+	case primitiveInteger, primitiveLong:
+		valid = isInteger(value)
+	case primitiveDouble:
+		valid = isDouble(value)
+	case primitiveBoolean:
+		valid = isBoolean(value)
+	case primitiveTimestamp:
+		valid = isTimestamp(value)
+	case primitiveJson:
+		valid = isJSON(value)
+	}
+
+	if !valid {
+		resourceValidation.AddError(path, "Property "+propertyName+" must be of type "+primitiveType+", but the value is: "+describeValue(value))
+	}
+	return valid
+}
+
+func checkIntrinsicResult(path string, propertyName string, value interface{}, function string, primitiveType string, resourceValidation *logger.ResourceValidation) bool {
+	if function == "Fn::If" {
+		return checkConditionalBranches(value, func(branch interface{}) bool {
+			return checkPrimitiveValue(path, propertyName, branch, primitiveType, resourceValidation)
+		})
+	}
+	if intrinsicResults[function] == listResult && primitiveType != primitiveJson {
+		resourceValidation.AddError(path, "Property "+propertyName+" must be of type "+primitiveType+", but "+function+" returns a List")
+		return false
+	}
+	return true
+}
+
+// checkConditionalBranches runs check on both values returned by Fn::If.
+func checkConditionalBranches(value interface{}, check func(branch interface{}) bool) bool {
+	arguments, ok := value.(map[string]interface{})["Fn::If"].([]interface{})
+	if !ok || len(arguments) != 3 {
+		return true
+	}
+	trueBranchValid := check(arguments[1])
+	falseBranchValid := check(arguments[2])
+	return trueBranchValid && falseBranchValid
+}
+
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, bool, int, int32, int64, float32, float64, json.Number:
+		return true
+	}
+	return false
+}
+
+func isInteger(value interface{}) bool {
+	switch number := value.(type) {
+	case int, int32, int64:
+		return true
+	case float64:
+		return number == math.Trunc(number)
+	case float32:
+		return float64(number) == math.Trunc(float64(number))
+	case json.Number:
+		_, err := number.Int64()
+		return err == nil
+	case string:
+		_, err := strconv.ParseInt(strings.TrimSpace(number), 10, 64)
+		return err == nil
+	}
+	return false
+}
+
+func isDouble(value interface{}) bool {
+	switch number := value.(type) {
+	case int, int32, int64, float32, float64, json.Number:
+		return true
+	case string:
+		_, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+		return err == nil
+	}
+	return false
+}
+
+func isBoolean(value interface{}) bool {
+	switch boolean := value.(type) {
+	case bool:
+		return true
+	case string:
+		lowerCase := strings.ToLower(boolean)
+		return lowerCase == "true" || lowerCase == "false"
+	}
+	return false
+}
+
+var timestampLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"}
+
+func isTimestamp(value interface{}) bool {
+	switch timestamp := value.(type) {
+	case time.Time:
+		return true
+	case string:
+		for _, layout := range timestampLayouts {
+			if _, err := time.Parse(layout, timestamp); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isJSON(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}, string: // CloudFormation accepts JSON documents serialized to String too.
+		return true
+	}
+	return false
+}
+
+func describeValue(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "a Map"
+	case []interface{}:
+		return "a List"
+	case string:
+		return "\"" + value.(string) + "\""
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,248 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Appliscale/perun/helpers"
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/specification"
+	"github.com/Appliscale/perun/validator/template"
+)
+
+// Pseudo parameters predefined by AWS CloudFormation.
+var pseudoParameters = []string{
+	"AWS::AccountId",
+	"AWS::NotificationARNs",
+	"AWS::NoValue",
+	"AWS::Partition",
+	"AWS::Region",
+	"AWS::StackId",
+	"AWS::StackName",
+	"AWS::URLSuffix",
+}
+
+// reference describes usage of another template element found in Ref, Fn::GetAtt or Fn::Sub.
+type reference struct {
+	Function  string
+	Target    string
+	Attribute string
+	Path      string
+}
+
+var subVariableRegex = regexp.MustCompile(`\$\{([^!}][^}]*)\}`)
+
+// findReferences looks for Ref, Fn::GetAtt and Fn::Sub in the value. Path describes where the value is placed.
+func findReferences(value interface{}, path string) (references []reference) {
+	switch element := value.(type) {
+	case map[string]interface{}:
+		if function, isFunction := isIntrinsicFunction(element); isFunction {
+			switch function {
+			case "Ref":
+				if target, ok := element[function].(string); ok {
+					return []reference{{Function: function, Target: target, Path: path}}
+				}
+			case "Fn::GetAtt":
+				if target, attribute, ok := getAttArguments(element[function]); ok {
+					references = append(references, reference{Function: function, Target: target, Attribute: attribute, Path: path})
+				}
+				if arguments, ok := element[function].([]interface{}); ok && len(arguments) == 2 {
+					references = append(references, findReferences(arguments[1], path)...)
+				}
+				return
+			case "Fn::Sub":
+				return findSubReferences(element[function], path)
+			}
+		}
+		keys := make([]string, 0, len(element))
+		for key := range element {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			references = append(references, findReferences(element[key], joinPath(path, key))...)
+		}
+	case []interface{}:
+		for index, item := range element {
+			references = append(references, findReferences(item, path+"["+strconv.Itoa(index)+"]")...)
+		}
+	}
+	return
+}
+
+func getAttArguments(arguments interface{}) (target string, attribute string, ok bool) {
+	switch value := arguments.(type) {
+	case string:
+		parts := strings.SplitN(value, ".", 2)
+		if len(parts) == 2 {
+			return parts[0], parts[1], true
+		}
+	case []interface{}:
+		if len(value) == 2 {
+			target, isTargetString := value[0].(string)
+			attribute, isAttributeString := value[1].(string)
+			return target, attribute, isTargetString && isAttributeString
+		}
+	}
+	return "", "", false
+}
+
+func findSubReferences(arguments interface{}, path string) (references []reference) {
+	var text string
+	variables := map[string]interface{}{}
+	switch value := arguments.(type) {
+	case string:
+		text = value
+	case []interface{}:
+		if len(value) > 0 {
+			text, _ = value[0].(string)
+		}
+		if len(value) > 1 {
+			variables, _ = value[1].(map[string]interface{})
+			references = append(references, findReferences(value[1], path)...)
+		}
+	}
+	for _, match := range subVariableRegex.FindAllStringSubmatch(text, -1) {
+		name := strings.TrimSpace(match[1])
+		if _, isVariable := variables[name]; isVariable {
+			continue
+		}
+		if parts := strings.SplitN(name, ".", 2); len(parts) == 2 {
+			references = append(references, reference{Function: "Fn::Sub", Target: parts[0], Attribute: parts[1], Path: path})
+		} else {
+			references = append(references, reference{Function: "Fn::Sub", Target: name, Path: path})
+		}
+	}
+	return
+}
+
+func joinPath(path string, element string) string {
+	if path == "" {
+		return element
+	}
+	return path + "." + element
+}
+
+// validateReferences checks if every Ref, Fn::GetAtt and Fn::Sub in Resources, Outputs and Conditions points at
+// an existing parameter, resource, pseudo parameter or resource attribute.
+func validateReferences(tmpl template.Template, spec *specification.Specification, sink logger.LoggerInt) bool {
+	valid := true
+	for _, resourceName := range sortedResourceNames(tmpl.Resources) {
+		references := findReferences(tmpl.Resources[resourceName].Properties, "Properties")
+		valid = checkReferences(references, tmpl, spec, resourceName, false, sink) && valid
+	}
+	for _, outputName := range sortedKeys(tmpl.Outputs) {
+		references := findReferences(tmpl.Outputs[outputName], outputName)
+		valid = checkReferences(references, tmpl, spec, "Outputs", false, sink) && valid
+	}
+	for _, conditionName := range sortedKeys(tmpl.Conditions) {
+		references := findReferences(tmpl.Conditions[conditionName], conditionName)
+		valid = checkReferences(references, tmpl, spec, "Conditions", true, sink) && valid
+	}
+	return valid
+}
+
+func checkReferences(references []reference, tmpl template.Template, spec *specification.Specification, elementName string, onlyParameters bool, sink logger.LoggerInt) bool {
+	valid := true
+	for _, ref := range references {
+		if message := checkReference(ref, tmpl, spec, onlyParameters); message != "" {
+			sink.AddResourceForValidation(elementName).AddError(ref.Path, ref.Path+": "+message)
+			valid = false
+		}
+	}
+	return valid
+}
+
+func checkReference(ref reference, tmpl template.Template, spec *specification.Specification, onlyParameters bool) string {
+	_, isParameter := tmpl.Parameters[ref.Target]
+	resource, isResource := tmpl.Resources[ref.Target]
+	isPseudoParameter := helpers.SliceContains(pseudoParameters, ref.Target)
+
+	if ref.Attribute == "" {
+		if isParameter || isPseudoParameter {
+			return ""
+		}
+		if isResource {
+			if onlyParameters {
+				return ref.Function + " to resource " + ref.Target + " is not allowed, only parameters can be referenced here"
+			}
+			return ""
+		}
+		candidates := append(append(sortedKeys(tmpl.Parameters), sortedResourceNames(tmpl.Resources)...), pseudoParameters...)
+		return ref.Function + " to undefined parameter or resource " + ref.Target + suggestion(ref.Target, candidates)
+	}
+
+	if !isResource {
+		if isParameter {
+			return ref.Function + " to attribute " + ref.Attribute + " of " + ref.Target + " which is a parameter, not a resource"
+		}
+		return ref.Function + " to undefined resource " + ref.Target + suggestion(ref.Target, sortedResourceNames(tmpl.Resources))
+	}
+	if onlyParameters {
+		return ref.Function + " to resource " + ref.Target + " is not allowed, only parameters can be referenced here"
+	}
+	if hasArbitraryAttributes(resource.Type, ref.Attribute) {
+		return ""
+	}
+	if resourceSpecification, ok := spec.ResourceTypes[resource.Type]; ok {
+		if _, hasAttribute := resourceSpecification.Attributes[ref.Attribute]; !hasAttribute {
+			attributes := make([]string, 0, len(resourceSpecification.Attributes))
+			for attribute := range resourceSpecification.Attributes {
+				attributes = append(attributes, attribute)
+			}
+			return ref.Function + " to attribute " + ref.Attribute + " which does not exist in " + resource.Type + suggestion(ref.Attribute, attributes)
+		}
+	}
+	return ""
+}
+
+// Custom resources return any attributes and nested stacks return their outputs as Outputs.<Name> attributes.
+func hasArbitraryAttributes(resourceType string, attribute string) bool {
+	if strings.HasPrefix(resourceType, "Custom::") || resourceType == "AWS::CloudFormation::CustomResource" {
+		return true
+	}
+	return resourceType == "AWS::CloudFormation::Stack" && strings.HasPrefix(attribute, "Outputs.")
+}
+
+func suggestion(name string, candidates []string) string {
+	if match, found := helpers.ClosestMatch(name, candidates); found {
+		return ". Did you mean " + match + "?"
+	}
+	return ""
+}
+
+func sortedKeys(elements map[string]interface{}) []string {
+	keys := make([]string, 0, len(elements))
+	for key := range elements {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedResourceNames(resources map[string]template.Resource) []string {
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,160 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"testing"
+
+	"github.com/Appliscale/perun/helpers"
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseTestTemplate(t *testing.T, templateBody string) template.Template {
+	sink := logger.CreateQuietLogger()
+	tmpl, err := helpers.ParseTemplate("template.yaml", []byte(templateBody), nil, &sink)
+	if err != nil {
+		t.Fatal("Error while parsing template: ", err)
+	}
+	return tmpl
+}
+
+func TestValidReferences(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Parameters:
+  Memory:
+    Type: Number
+Resources:
+  Function:
+    Type: AWS::Primitive1::Function
+    Properties:
+      MemorySize: !Ref Memory
+  OtherFunction:
+    Type: AWS::Primitive1::Function
+    Properties:
+      Name: !Sub "${AWS::StackName}-${Function}-${Function.Arn}"
+      Address: !GetAtt Function.Endpoint.Address
+Outputs:
+  FunctionArn:
+    Value: !GetAtt [Function, Arn]
+`)
+
+	assert.True(t, validateReferences(tmpl, &spec, &sink))
+	assert.False(t, sink.HasValidationErrors())
+}
+
+func TestUndefinedRef(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Parameters:
+  Memory:
+    Type: Number
+Resources:
+  Function:
+    Type: AWS::Primitive1::Function
+    Properties:
+      MemorySize: !Ref Memroy
+`)
+
+	assert.False(t, validateReferences(tmpl, &spec, &sink))
+	assert.Equal(t, []string{"Properties.MemorySize: Ref to undefined parameter or resource Memroy. Did you mean Memory?"},
+		sink.AddResourceForValidation("Function").Errors)
+}
+
+func TestGetAttToUnknownAttribute(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := parseTestTemplate(t, `
+Resources:
+  Function:
+    Type: AWS::Primitive1::Function
+Outputs:
+  FunctionArn:
+    Value: !GetAtt Function.Arm
+`)
+
+	assert.False(t, validateReferences(tmpl, &spec, &sink))
+	assert.Equal(t, []string{"FunctionArn.Value: Fn::GetAtt to attribute Arm which does not exist in AWS::Primitive1::Function. Did you mean Arn?"},
+		sink.AddResourceForValidation("Outputs").Errors)
+}
+
+func TestGetAttToParameter(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := template.Template{
+		Parameters: map[string]interface{}{"Memory": map[string]interface{}{"Type": "Number"}},
+		Outputs: map[string]interface{}{
+			"Output": map[string]interface{}{
+				"Value": map[string]interface{}{"Fn::GetAtt": []interface{}{"Memory", "Arn"}},
+			},
+		},
+	}
+
+	assert.False(t, validateReferences(tmpl, &spec, &sink))
+}
+
+func TestSubReferenceToUndefinedResource(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := template.Template{
+		Resources: map[string]template.Resource{
+			"Function": {
+				Type: "AWS::Primitive1::Function",
+				Properties: map[string]interface{}{
+					"Name": map[string]interface{}{
+						"Fn::Sub": []interface{}{"${Prefix}-${!Literal}-${Missing.Arn}", map[string]interface{}{"Prefix": "perun"}},
+					},
+				},
+			},
+		},
+	}
+
+	assert.False(t, validateReferences(tmpl, &spec, &sink))
+	assert.Equal(t, []string{"Properties.Name: Fn::Sub to undefined resource Missing"},
+		sink.AddResourceForValidation("Function").Errors)
+}
+
+func TestArbitraryAttributesOfCustomResourcesAndStacks(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := template.Template{
+		Resources: map[string]template.Resource{
+			"Custom": {Type: "Custom::Lookup"},
+			"Nested": {Type: "AWS::CloudFormation::Stack"},
+		},
+		Outputs: map[string]interface{}{
+			"Custom": map[string]interface{}{"Value": map[string]interface{}{"Fn::GetAtt": []interface{}{"Custom", "Anything"}}},
+			"Nested": map[string]interface{}{"Value": map[string]interface{}{"Fn::GetAtt": []interface{}{"Nested", "Outputs.Vpc"}}},
+		},
+	}
+
+	assert.True(t, validateReferences(tmpl, &spec, &sink))
+}
+
+func TestRefToResourceInConditions(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	tmpl := template.Template{
+		Resources: map[string]template.Resource{
+			"Function": {Type: "AWS::Primitive1::Function"},
+		},
+		Conditions: map[string]interface{}{
+			"IsProduction": map[string]interface{}{
+				"Fn::Equals": []interface{}{map[string]interface{}{"Ref": "Function"}, "production"},
+			},
+		},
+	}
+
+	assert.False(t, validateReferences(tmpl, &spec, &sink))
+}
@@ -25,16 +25,22 @@ type Template struct {
 	Parameters               map[string]interface{} `yaml:"Parameters"`
 	Mappings                 map[string]interface{} `yaml:"Mappings"`
 	Conditions               map[string]interface{} `yaml:"Conditions"`
-	Transform                map[string]interface{} `yaml:"Transform"`
+	Transform                interface{}            `yaml:"Transform"`
 	Resources                map[string]Resource    `yaml:"Resources"`
 	Outputs                  map[string]interface{} `yaml:"Outputs"`
 }
 
 // Resource describes structure of Resources in Template.
 type Resource struct {
-	Type           string                 `yaml:"Type"`
-	Properties     map[string]interface{} `yaml:"Properties"`
-	DeletionPolicy string                 `yaml:"DeletionPolicy"`
+	Type                string                 `yaml:"Type"`
+	Properties          map[string]interface{} `yaml:"Properties"`
+	DeletionPolicy      interface{}            `yaml:"DeletionPolicy"`
+	UpdateReplacePolicy interface{}            `yaml:"UpdateReplacePolicy"`
+	DependsOn           interface{}            `yaml:"DependsOn"`
+	Condition           string                 `yaml:"Condition"`
+	CreationPolicy      interface{}            `yaml:"CreationPolicy"`
+	UpdatePolicy        interface{}            `yaml:"UpdatePolicy"`
+	Metadata            map[string]interface{} `yaml:"Metadata"`
 }
 
 // Parameters describes structure of Parameters in Template.
@@ -0,0 +1,167 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Appliscale/perun/context"
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/specification"
+)
+
+// templateValidation contains results of validation of a single template, which is one of many validated templates.
+type templateValidation struct {
+	templatePath string
+	valid        bool
+	logger       *logger.Logger
+	// Log messages (and validation results in text output format), printed after all templates are validated.
+	output bytes.Buffer
+}
+
+// validateTemplates validates templates concurrently with a pool of workers sharing the resource specification.
+// Results are printed in the order of templates, followed by a summary. It returns true if all templates are valid.
+func validateTemplates(templatePaths []string, resourceSpecification *specification.Specification, ctx *context.Context) bool {
+	jobs := runtime.NumCPU()
+	if ctx.CliArguments.Jobs != nil && *ctx.CliArguments.Jobs > 0 {
+		jobs = *ctx.CliArguments.Jobs
+	}
+	if jobs > len(templatePaths) {
+		jobs = len(templatePaths)
+	}
+
+	validations := make([]*templateValidation, len(templatePaths))
+	indexes := make(chan int)
+	var workers sync.WaitGroup
+	for worker := 0; worker < jobs; worker++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for index := range indexes {
+				validations[index] = validateTemplateConcurrently(templatePaths[index], resourceSpecification, ctx)
+			}
+		}()
+	}
+	for index := range templatePaths {
+		indexes <- index
+	}
+	close(indexes)
+	workers.Wait()
+
+	return printTemplateValidations(validations, ctx)
+}
+
+// validateTemplateConcurrently validates the template using a copy of the context with its own logger,
+// which writes to a buffer, so output of templates validated at the same time isn't mixed.
+func validateTemplateConcurrently(templatePath string, resourceSpecification *specification.Specification, ctx *context.Context) *templateValidation {
+	validation := &templateValidation{templatePath: templatePath}
+	validation.logger = createTemplateLogger(ctx, &validation.output)
+
+	templateContext := *ctx
+	templateContext.Logger = validation.logger
+	templatePathCopy := templatePath
+	templateContext.CliArguments.TemplatePath = &templatePathCopy
+
+	if validation.logger.IsMachineReadable() {
+		validation.valid = checkTemplateFile(templatePath, templatePath, nil, resourceSpecification, &templateContext)
+	} else {
+		validation.valid = validateTemplateFile(templatePath, templatePath, resourceSpecification, &templateContext)
+	}
+	return validation
+}
+
+// createTemplateLogger creates a logger configured like the main one, which writes to the output.
+func createTemplateLogger(ctx *context.Context, output io.Writer) *logger.Logger {
+	templateLogger := logger.CreateDefaultLogger()
+	if ctx.CliArguments.Quiet != nil {
+		templateLogger.Quiet = *ctx.CliArguments.Quiet
+	}
+	if ctx.CliArguments.Yes != nil {
+		templateLogger.Yes = *ctx.CliArguments.Yes
+	}
+	if ctx.CliArguments.OutputFormat != nil {
+		templateLogger.OutputFormat = *ctx.CliArguments.OutputFormat
+	}
+	templateLogger.SetVerbosity(ctx.Config.DefaultVerbosity)
+	templateLogger.Output = output
+	return &templateLogger
+}
+
+// printTemplateValidations prints output of every template and a summary. In machine-readable output formats
+// log messages are printed to standard error and results of all templates are printed as one report.
+func printTemplateValidations(validations []*templateValidation, ctx *context.Context) bool {
+	machineReadable := false
+	allValid := true
+	summary := []string{"Summary:"}
+	validCount, invalidCount, warningsCount := 0, 0, 0
+
+	for _, validation := range validations {
+		machineReadable = validation.logger.IsMachineReadable()
+		if machineReadable {
+			os.Stderr.Write(validation.output.Bytes())
+			for _, resourceValidation := range validation.logger.GetResourceValidations() {
+				resourceValidation.TemplatePath = validation.templatePath
+			}
+			ctx.Logger.AppendResourceValidations(validation.logger.GetResourceValidations())
+		} else if validation.output.Len() > 0 {
+			ctx.Logger.Always(strings.TrimSuffix(validation.output.String(), "\n"))
+		}
+
+		errors, warnings := countValidationMessages(validation.logger.GetResourceValidations())
+		counts := strconv.Itoa(errors) + " error(s), " + strconv.Itoa(warnings) + " warning(s)"
+		if !validation.valid {
+			allValid = false
+			invalidCount++
+			summary = append(summary, fmt.Sprintf("    %s: invalid (%s)", validation.templatePath, counts))
+		} else if warnings > 0 {
+			warningsCount++
+			summary = append(summary, fmt.Sprintf("    %s: valid with warnings (%s)", validation.templatePath, counts))
+		} else {
+			validCount++
+			summary = append(summary, fmt.Sprintf("    %s: valid", validation.templatePath))
+		}
+	}
+	summary = append(summary, fmt.Sprintf("%d templates validated: %d valid, %d valid with warnings, %d invalid",
+		len(validations), validCount, warningsCount, invalidCount))
+
+	if machineReadable {
+		ctx.Logger.PrintValidationErrors()
+		for _, line := range summary {
+			ctx.Logger.Info(line)
+		}
+	} else if ctx.CliArguments.Quiet == nil || !*ctx.CliArguments.Quiet {
+		for _, line := range summary {
+			ctx.Logger.Always(line)
+		}
+	}
+	return allValid
+}
+
+func countValidationMessages(validations []*logger.ResourceValidation) (errors int, warnings int) {
+	for _, validation := range validations {
+		errors += len(validation.Errors)
+		warnings += len(validation.Warnings)
+	}
+	return
+}
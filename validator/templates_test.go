@@ -0,0 +1,102 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Appliscale/perun/cliparser"
+	"github.com/Appliscale/perun/context"
+	"github.com/Appliscale/perun/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTemplatesContext(t *testing.T, templates map[string]string, outputFormat string) (*context.Context, []string, *bytes.Buffer) {
+	directory, err := ioutil.TempDir("", "perun-templates")
+	assert.Nil(t, err)
+	var templatePaths []string
+	for name, body := range templates {
+		templatePath := filepath.Join(directory, name)
+		assert.Nil(t, ioutil.WriteFile(templatePath, []byte(body), 0644))
+		templatePaths = append(templatePaths, templatePath)
+	}
+
+	offline := true
+	jobs := 2
+	output := &bytes.Buffer{}
+	return &context.Context{
+		CliArguments: cliparser.CliArguments{Offline: &offline, Jobs: &jobs, OutputFormat: &outputFormat},
+		Logger:       &logger.Logger{OutputFormat: outputFormat, Output: output},
+	}, templatePaths, output
+}
+
+const validTemplate = `Resources:
+  Example:
+    Type: ExampleResourceType
+    Properties:
+      ExampleProperty: value
+`
+
+const invalidTemplate = `Resources:
+  Example:
+    Type: ExampleResourceType
+    Properties:
+      OtherProperty: value
+`
+
+func TestValidateManyTemplates(t *testing.T) {
+	ctx, templatePaths, output := createTemplatesContext(t, map[string]string{"valid.yaml": validTemplate, "valid.yml": validTemplate}, logger.TextFormat)
+	defer os.RemoveAll(filepath.Dir(templatePaths[0]))
+
+	assert.True(t, validateTemplates(templatePaths, &spec, ctx))
+	assert.Contains(t, output.String(), "2 templates validated: 2 valid, 0 valid with warnings, 0 invalid")
+}
+
+func TestValidateManyTemplatesWithInvalidOne(t *testing.T) {
+	ctx, templatePaths, output := createTemplatesContext(t, map[string]string{"valid.yaml": validTemplate, "invalid.yaml": invalidTemplate}, logger.TextFormat)
+	defer os.RemoveAll(filepath.Dir(templatePaths[0]))
+
+	assert.False(t, validateTemplates(templatePaths, &spec, ctx))
+	result := output.String()
+	assert.Contains(t, result, "Template "+filepath.Join(filepath.Dir(templatePaths[0]), "invalid.yaml")+" is invalid!")
+	assert.Contains(t, result, "invalid.yaml: invalid (")
+	assert.Contains(t, result, "2 templates validated: 1 valid, 0 valid with warnings, 1 invalid")
+	// Output of every template is printed in the order of templates, before the summary.
+	for _, templatePath := range templatePaths {
+		assert.True(t, strings.Index(result, "Template "+templatePath) < strings.Index(result, "Summary:"))
+	}
+}
+
+func TestValidateManyTemplatesInMachineReadableFormat(t *testing.T) {
+	ctx, templatePaths, output := createTemplatesContext(t, map[string]string{"valid.yaml": validTemplate, "invalid.yaml": invalidTemplate}, logger.JSONFormat)
+	defer os.RemoveAll(filepath.Dir(templatePaths[0]))
+
+	assert.False(t, validateTemplates(templatePaths, &spec, ctx))
+	result := output.String()
+	assert.True(t, strings.HasPrefix(result, "{"), "Report should be printed once, before the summary")
+	assert.Equal(t, 1, strings.Count(result, "\"findings\""))
+	assert.Contains(t, result, "invalid.yaml")
+	assert.NotEmpty(t, ctx.Logger.GetResourceValidations())
+	for _, resourceValidation := range ctx.Logger.GetResourceValidations() {
+		assert.Contains(t, templatePaths, resourceValidation.TemplatePath)
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"strconv"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Limits of secondary indexes per DynamoDB table.
+const (
+	maxGlobalSecondaryIndexes = 20
+	maxLocalSecondaryIndexes  = 5
+)
+
+// DynamoDBTableProperties describes structure of DynamoDB Table.
+type DynamoDBTableProperties struct {
+	TableName              string
+	AttributeDefinitions   []AttributeDefinition
+	KeySchema              []KeySchemaElement
+	GlobalSecondaryIndexes []SecondaryIndex
+	LocalSecondaryIndexes  []SecondaryIndex
+	BillingMode            string
+}
+
+// AttributeDefinition describes attribute used in key schemas.
+type AttributeDefinition struct {
+	AttributeName string
+	AttributeType string
+}
+
+// KeySchemaElement describes one of table or index keys.
+type KeySchemaElement struct {
+	AttributeName string
+	KeyType       string
+}
+
+// SecondaryIndex describes global or local secondary index.
+type SecondaryIndex struct {
+	IndexName string
+	KeySchema []KeySchemaElement
+}
+
+// IsDynamoDBTableValid : Checks if key schemas of the table and its indexes are consistent with attribute definitions and billing mode.
+func IsDynamoDBTableValid(table template.Resource, resourceValidation *logger.ResourceValidation) bool {
+	var properties DynamoDBTableProperties
+	mapstructure.Decode(table.Properties, &properties)
+
+	errors := []pathError{}
+	// Attributes defined in AttributeDefinitions, marked when they are used in a key schema.
+	definitions := make(map[string]bool)
+	for index, definition := range properties.AttributeDefinitions {
+		path := "Properties.AttributeDefinitions[" + strconv.Itoa(index) + "]"
+		if _, defined := definitions[definition.AttributeName]; defined && definition.AttributeName != "" {
+			errors = append(errors, pathError{path, "Attribute " + definition.AttributeName + " is defined more than once"})
+		}
+		definitions[definition.AttributeName] = false
+		if definition.AttributeType != "" && definition.AttributeType != "S" && definition.AttributeType != "N" && definition.AttributeType != "B" {
+			errors = append(errors, pathError{path + ".AttributeType", "AttributeType has to be S, N or B"})
+		}
+	}
+
+	keySchemas := map[string][]KeySchemaElement{"Properties.KeySchema": properties.KeySchema}
+	paths := []string{"Properties.KeySchema"}
+	for index, gsi := range properties.GlobalSecondaryIndexes {
+		path := "Properties.GlobalSecondaryIndexes[" + strconv.Itoa(index) + "].KeySchema"
+		keySchemas[path] = gsi.KeySchema
+		paths = append(paths, path)
+	}
+	for index, lsi := range properties.LocalSecondaryIndexes {
+		path := "Properties.LocalSecondaryIndexes[" + strconv.Itoa(index) + "].KeySchema"
+		keySchemas[path] = lsi.KeySchema
+		paths = append(paths, path)
+	}
+	// Key schemas and attribute definitions set with intrinsic functions can't be checked before deployment.
+	unresolved := findUnresolvedKeySchemas(table.Properties)
+	definitionsResolved := !isUnresolvedList(table.Properties["AttributeDefinitions"])
+	for _, path := range paths {
+		if !unresolved[path] {
+			errors = append(errors, checkKeySchema(keySchemas[path], path, definitions, definitionsResolved)...)
+		}
+	}
+	for _, definition := range properties.AttributeDefinitions {
+		if !definitionsResolved || len(unresolved) > 0 {
+			break
+		}
+		if used, defined := definitions[definition.AttributeName]; defined && !used && definition.AttributeName != "" {
+			errors = append(errors, pathError{"Properties.AttributeDefinitions", "Attribute " + definition.AttributeName + " is defined, but it's not used in any key schema"})
+			definitions[definition.AttributeName] = true
+		}
+	}
+
+	errors = append(errors, checkSecondaryIndexes(properties, unresolved)...)
+	errors = append(errors, checkBillingMode(table.Properties, properties)...)
+
+	for _, e := range errors {
+		resourceValidation.AddError(e.path, e.String())
+	}
+	return len(errors) == 0
+}
+
+// checkKeySchema checks if the key schema has one HASH key, at most one RANGE key and, if attribute definitions
+// are known, uses only defined attributes.
+func checkKeySchema(keySchema []KeySchemaElement, path string, definitions map[string]bool, checkDefinitions bool) (errors []pathError) {
+	hashKeys, rangeKeys := 0, 0
+	for index, key := range keySchema {
+		switch key.KeyType {
+		case "HASH":
+			hashKeys++
+		case "RANGE":
+			rangeKeys++
+		case "":
+		default:
+			errors = append(errors, pathError{path + "[" + strconv.Itoa(index) + "].KeyType", "KeyType has to be HASH or RANGE"})
+		}
+		if key.AttributeName == "" || !checkDefinitions {
+			continue
+		}
+		if _, defined := definitions[key.AttributeName]; !defined {
+			errors = append(errors, pathError{path + "[" + strconv.Itoa(index) + "].AttributeName", "Attribute " + key.AttributeName + " is not defined in AttributeDefinitions"})
+		} else {
+			definitions[key.AttributeName] = true
+		}
+	}
+	if hashKeys != 1 || rangeKeys > 1 {
+		errors = append(errors, pathError{path, "Key schema has to contain exactly one HASH key and at most one RANGE key"})
+	}
+	return
+}
+
+func checkSecondaryIndexes(properties DynamoDBTableProperties, unresolved map[string]bool) (errors []pathError) {
+	if len(properties.GlobalSecondaryIndexes) > maxGlobalSecondaryIndexes {
+		errors = append(errors, pathError{"Properties.GlobalSecondaryIndexes", "Table can have at most " + strconv.Itoa(maxGlobalSecondaryIndexes) +
+			" global secondary indexes, but it has " + strconv.Itoa(len(properties.GlobalSecondaryIndexes))})
+	}
+	if len(properties.LocalSecondaryIndexes) > maxLocalSecondaryIndexes {
+		errors = append(errors, pathError{"Properties.LocalSecondaryIndexes", "Table can have at most " + strconv.Itoa(maxLocalSecondaryIndexes) +
+			" local secondary indexes, but it has " + strconv.Itoa(len(properties.LocalSecondaryIndexes))})
+	}
+
+	indexNames := make(map[string]bool)
+	checkIndexName := func(name string, path string) {
+		if name != "" && indexNames[name] {
+			errors = append(errors, pathError{path + ".IndexName", "Index name " + name + " is used more than once"})
+		}
+		indexNames[name] = true
+	}
+	for index, gsi := range properties.GlobalSecondaryIndexes {
+		checkIndexName(gsi.IndexName, "Properties.GlobalSecondaryIndexes["+strconv.Itoa(index)+"]")
+	}
+
+	tableHashKey := getKey(properties.KeySchema, "HASH")
+	for index, lsi := range properties.LocalSecondaryIndexes {
+		path := "Properties.LocalSecondaryIndexes[" + strconv.Itoa(index) + "]"
+		checkIndexName(lsi.IndexName, path)
+		if unresolved[path+".KeySchema"] {
+			continue
+		}
+		if hashKey := getKey(lsi.KeySchema, "HASH"); hashKey != "" && tableHashKey != "" && hashKey != tableHashKey {
+			errors = append(errors, pathError{path + ".KeySchema", "Local secondary index has to use the table HASH key " + tableHashKey + ", but it uses " + hashKey})
+		}
+		if getKey(lsi.KeySchema, "RANGE") == "" {
+			errors = append(errors, pathError{path + ".KeySchema", "Local secondary index has to contain a RANGE key"})
+		}
+	}
+	return
+}
+
+// findUnresolvedKeySchemas finds paths of key schemas of the table and its indexes which are set with intrinsic functions.
+func findUnresolvedKeySchemas(rawProperties map[string]interface{}) map[string]bool {
+	unresolved := make(map[string]bool)
+	if isUnresolvedList(rawProperties["KeySchema"]) {
+		unresolved["Properties.KeySchema"] = true
+	}
+	for _, indexes := range []string{"GlobalSecondaryIndexes", "LocalSecondaryIndexes"} {
+		list, _ := rawProperties[indexes].([]interface{})
+		for index, item := range list {
+			secondaryIndex, _ := item.(map[string]interface{})
+			if isFunction(item) || isUnresolvedList(secondaryIndex["KeySchema"]) {
+				unresolved["Properties."+indexes+"["+strconv.Itoa(index)+"].KeySchema"] = true
+			}
+		}
+	}
+	return unresolved
+}
+
+// isUnresolvedList checks if the list or any of its items is set with an intrinsic function.
+func isUnresolvedList(value interface{}) bool {
+	if isFunction(value) {
+		return true
+	}
+	list, _ := value.([]interface{})
+	for _, item := range list {
+		if isFunction(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBillingMode checks if provisioned throughput is set for the table and its global secondary indexes only in PROVISIONED mode.
+func checkBillingMode(rawProperties map[string]interface{}, properties DynamoDBTableProperties) (errors []pathError) {
+	if _, isSet := rawProperties["BillingMode"]; isSet && properties.BillingMode == "" {
+		// Billing mode is set with an intrinsic function.
+		return
+	}
+	_, hasThroughput := rawProperties["ProvisionedThroughput"]
+	rawIndexes, _ := rawProperties["GlobalSecondaryIndexes"].([]interface{})
+	switch properties.BillingMode {
+	case "PAY_PER_REQUEST":
+		if hasThroughput {
+			errors = append(errors, pathError{"Properties.ProvisionedThroughput", "ProvisionedThroughput can't be set when BillingMode is PAY_PER_REQUEST"})
+		}
+		for index, rawIndex := range rawIndexes {
+			if isFunction(rawIndex) {
+				continue
+			}
+			if _, hasIndexThroughput := toProperties(rawIndex)["ProvisionedThroughput"]; hasIndexThroughput {
+				errors = append(errors, pathError{"Properties.GlobalSecondaryIndexes[" + strconv.Itoa(index) + "].ProvisionedThroughput", "ProvisionedThroughput can't be set when BillingMode is PAY_PER_REQUEST"})
+			}
+		}
+	case "", "PROVISIONED":
+		if !hasThroughput {
+			errors = append(errors, pathError{"Properties", "ProvisionedThroughput is required when BillingMode is PROVISIONED"})
+		}
+		for index, rawIndex := range rawIndexes {
+			if isFunction(rawIndex) {
+				continue
+			}
+			if _, hasIndexThroughput := toProperties(rawIndex)["ProvisionedThroughput"]; !hasIndexThroughput {
+				errors = append(errors, pathError{"Properties.GlobalSecondaryIndexes[" + strconv.Itoa(index) + "]", "ProvisionedThroughput is required when BillingMode is PROVISIONED"})
+			}
+		}
+	default:
+		errors = append(errors, pathError{"Properties.BillingMode", "BillingMode has to be PROVISIONED or PAY_PER_REQUEST"})
+	}
+	return
+}
+
+func toProperties(value interface{}) map[string]interface{} {
+	properties, _ := value.(map[string]interface{})
+	return properties
+}
+
+func getKey(keySchema []KeySchemaElement, keyType string) string {
+	for _, key := range keySchema {
+		if key.KeyType == keyType {
+			return key.AttributeName
+		}
+	}
+	return ""
+}
@@ -0,0 +1,174 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"testing"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidDynamoDBTable(t *testing.T) {
+	table := createResourceFromYaml(t, `
+Type: AWS::DynamoDB::Table
+Properties:
+  AttributeDefinitions:
+    - {AttributeName: Id, AttributeType: S}
+    - {AttributeName: Created, AttributeType: N}
+    - {AttributeName: Owner, AttributeType: S}
+  KeySchema:
+    - {AttributeName: Id, KeyType: HASH}
+  BillingMode: PAY_PER_REQUEST
+  GlobalSecondaryIndexes:
+    - IndexName: ByOwner
+      KeySchema:
+        - {AttributeName: Owner, KeyType: HASH}
+        - {AttributeName: Created, KeyType: RANGE}
+      Projection: {ProjectionType: ALL}
+`)
+	resourceValidation := logger.ResourceValidation{ResourceName: "Table"}
+
+	assert.True(t, IsDynamoDBTableValid(table, &resourceValidation))
+	assert.Empty(t, resourceValidation.Errors)
+}
+
+func TestDynamoDBAttributeDefinitions(t *testing.T) {
+	table := createResourceFromYaml(t, `
+Type: AWS::DynamoDB::Table
+Properties:
+  AttributeDefinitions:
+    - {AttributeName: Id, AttributeType: S}
+    - {AttributeName: Unused, AttributeType: X}
+  KeySchema:
+    - {AttributeName: Id, KeyType: HASH}
+    - {AttributeName: Created, KeyType: RANGE}
+  ProvisionedThroughput: {ReadCapacityUnits: 5, WriteCapacityUnits: 5}
+`)
+	resourceValidation := logger.ResourceValidation{ResourceName: "Table"}
+
+	assert.False(t, IsDynamoDBTableValid(table, &resourceValidation))
+	assert.Equal(t, []string{
+		"Properties.AttributeDefinitions[1].AttributeType: AttributeType has to be S, N or B",
+		"Properties.KeySchema[1].AttributeName: Attribute Created is not defined in AttributeDefinitions",
+		"Properties.AttributeDefinitions: Attribute Unused is defined, but it's not used in any key schema",
+	}, resourceValidation.Errors)
+}
+
+func TestDynamoDBSecondaryIndexes(t *testing.T) {
+	table := createResourceFromYaml(t, `
+Type: AWS::DynamoDB::Table
+Properties:
+  AttributeDefinitions:
+    - {AttributeName: Id, AttributeType: S}
+    - {AttributeName: Owner, AttributeType: S}
+  KeySchema:
+    - {AttributeName: Id, KeyType: HASH}
+  ProvisionedThroughput: {ReadCapacityUnits: 5, WriteCapacityUnits: 5}
+  GlobalSecondaryIndexes:
+    - IndexName: ByOwner
+      KeySchema:
+        - {AttributeName: Owner, KeyType: HASH}
+      Projection: {ProjectionType: ALL}
+  LocalSecondaryIndexes:
+    - IndexName: ByOwner
+      KeySchema:
+        - {AttributeName: Owner, KeyType: HASH}
+      Projection: {ProjectionType: ALL}
+`)
+	resourceValidation := logger.ResourceValidation{ResourceName: "Table"}
+
+	assert.False(t, IsDynamoDBTableValid(table, &resourceValidation))
+	assert.Equal(t, []string{
+		"Properties.LocalSecondaryIndexes[0].IndexName: Index name ByOwner is used more than once",
+		"Properties.LocalSecondaryIndexes[0].KeySchema: Local secondary index has to use the table HASH key Id, but it uses Owner",
+		"Properties.LocalSecondaryIndexes[0].KeySchema: Local secondary index has to contain a RANGE key",
+		"Properties.GlobalSecondaryIndexes[0]: ProvisionedThroughput is required when BillingMode is PROVISIONED",
+	}, resourceValidation.Errors)
+}
+
+func TestDynamoDBPayPerRequestWithThroughput(t *testing.T) {
+	table := createResourceFromYaml(t, `
+Type: AWS::DynamoDB::Table
+Properties:
+  AttributeDefinitions:
+    - {AttributeName: Id, AttributeType: S}
+  KeySchema:
+    - {AttributeName: Id, KeyType: HASH}
+    - {AttributeName: Id, KeyType: HASH}
+  BillingMode: PAY_PER_REQUEST
+  ProvisionedThroughput: {ReadCapacityUnits: 5, WriteCapacityUnits: 5}
+`)
+	resourceValidation := logger.ResourceValidation{ResourceName: "Table"}
+
+	assert.False(t, IsDynamoDBTableValid(table, &resourceValidation))
+	assert.Equal(t, []string{
+		"Properties.KeySchema: Key schema has to contain exactly one HASH key and at most one RANGE key",
+		"Properties.ProvisionedThroughput: ProvisionedThroughput can't be set when BillingMode is PAY_PER_REQUEST",
+	}, resourceValidation.Errors)
+}
+
+func TestDynamoDBKeySchemaWithIntrinsicFunctions(t *testing.T) {
+	table := createResourceFromYaml(t, `
+Type: AWS::DynamoDB::Table
+Properties:
+  AttributeDefinitions:
+    Fn::If:
+      - WithRange
+      - [{AttributeName: Id, AttributeType: S}, {AttributeName: Created, AttributeType: N}]
+      - [{AttributeName: Id, AttributeType: S}]
+  KeySchema:
+    - {AttributeName: Id, KeyType: HASH}
+    - Fn::If: [WithRange, {AttributeName: Created, KeyType: RANGE}, Ref: AWS::NoValue]
+  BillingMode: PAY_PER_REQUEST
+  LocalSecondaryIndexes:
+    - IndexName: ByCreated
+      KeySchema: {Ref: IndexKeySchema}
+      Projection: {ProjectionType: ALL}
+`)
+	resourceValidation := logger.ResourceValidation{ResourceName: "Table"}
+
+	assert.True(t, IsDynamoDBTableValid(table, &resourceValidation))
+	assert.Empty(t, resourceValidation.Errors)
+}
+
+func TestDynamoDBSecondaryIndexesWithIntrinsicFunctions(t *testing.T) {
+	table := createResourceFromYaml(t, `
+Type: AWS::DynamoDB::Table
+Properties:
+  AttributeDefinitions:
+    - {AttributeName: Id, AttributeType: S}
+    - {AttributeName: Owner, AttributeType: S}
+  KeySchema:
+    - {AttributeName: Id, KeyType: HASH}
+  BillingMode: PROVISIONED
+  ProvisionedThroughput: {ReadCapacityUnits: 5, WriteCapacityUnits: 5}
+  GlobalSecondaryIndexes:
+    - Fn::If:
+        - WithOwnerIndex
+        - IndexName: ByOwner
+          KeySchema:
+            - {AttributeName: Owner, KeyType: HASH}
+          Projection: {ProjectionType: ALL}
+          ProvisionedThroughput: {ReadCapacityUnits: 5, WriteCapacityUnits: 5}
+        - Ref: AWS::NoValue
+`)
+	resourceValidation := logger.ResourceValidation{ResourceName: "Table"}
+
+	assert.True(t, IsDynamoDBTableValid(table, &resourceValidation))
+	assert.Empty(t, resourceValidation.Errors)
+}
@@ -1,53 +1,109 @@
 package validators
 
 import (
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/Appliscale/perun/context"
+	"github.com/Appliscale/perun/helpers"
 	"github.com/Appliscale/perun/logger"
 	"github.com/Appliscale/perun/validator/template"
-	"strconv"
-	"strings"
 )
 
 type Restrictor func(string) (bool, string)
 
 var defaultRestrictor Restrictor = func(propertyName string) (valid bool, msg string) { return true, "Should pass" }
 
-func GetRestrictor(key string, ctx *context.Context) Restrictor {
-	return defaultRestrictor
+// GetRestrictor returns restrictor of the property value based on the restrictions configuration. Property path
+// consists of property names separated with dots, without list indices (e.g. Tags.Key).
+func GetRestrictor(resourceType string, propertyPath string, ctx *context.Context) Restrictor {
+	restriction, ok := ctx.RestrictionsConfig.GetRestriction(resourceType, propertyPath)
+	if !ok {
+		return defaultRestrictor
+	}
+	return func(value string) (bool, string) {
+		if len(restriction.AllowedValues) > 0 && !helpers.SliceContains(restriction.AllowedValues, value) {
+			return false, "The value has to be one of: " + strings.Join(restriction.AllowedValues, ", ")
+		}
+		if restriction.MinLength != nil && len(value) < *restriction.MinLength {
+			return false, "The value has to be at least " + strconv.Itoa(*restriction.MinLength) + " characters long"
+		}
+		if restriction.MaxLength != nil && len(value) > *restriction.MaxLength {
+			return false, "The value has to be at most " + strconv.Itoa(*restriction.MaxLength) + " characters long"
+		}
+		if !restriction.MatchesPattern(value) {
+			return false, "The value has to match the pattern " + restriction.Pattern
+		}
+		if restriction.MinValue != nil || restriction.MaxValue != nil {
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return false, "The value has to be a number"
+			}
+			if restriction.MinValue != nil && number < *restriction.MinValue {
+				return false, "The value has to be at least " + formatFloat(*restriction.MinValue)
+			}
+			if restriction.MaxValue != nil && number > *restriction.MaxValue {
+				return false, "The value has to be at most " + formatFloat(*restriction.MaxValue)
+			}
+		}
+		return true, ""
+	}
+}
+
+// pathError is a validation error of the element under path, relative to the validated resource.
+type pathError struct {
+	path    string
+	message string
+}
+
+func (e pathError) String() string {
+	if e.path == "" {
+		return e.message
+	}
+	return e.path + ": " + e.message
+}
+
+func formatFloat(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
 }
 
 func GeneralValidateResourceByName(resourceValue template.Resource, resourceValidation *logger.ResourceValidation, ctx *context.Context) {
-	for propertyName, propertyValue := range resourceValue.Properties {
-		analyzeObject(propertyName, propertyValue, resourceValidation, propertyName+" ", ctx)
+	for _, propertyName := range sortedPropertyNames(resourceValue.Properties) {
+		analyzeObject(resourceValue.Type, propertyName, "Properties."+propertyName, resourceValue.Properties[propertyName], resourceValidation, propertyName+" ", ctx)
 	}
 }
 
-func analyzeObject(propertyName string, propertyValue interface{}, resourceValidation *logger.ResourceValidation, preMessage string, ctx *context.Context) {
+// PropertyPath is the path used to find property restrictions, elementPath is the path of the value inside the resource.
+func analyzeObject(resourceType string, propertyPath string, elementPath string, propertyValue interface{}, resourceValidation *logger.ResourceValidation, preMessage string, ctx *context.Context) {
 	var propertyRestrictor Restrictor
 	switch propertyValue.(type) {
-	case string:
-		propertyRestrictor = GetRestrictor(propertyName, ctx)
-		if valid, msg := propertyRestrictor(propertyValue.(string)); !valid {
-			resourceValidation.AddValidationWarning(preMessage + ": " + msg + ", but the value is: \"" + propertyValue.(string) + "\"")
+	case string, float64, bool:
+		value := scalarToString(propertyValue)
+		propertyRestrictor = GetRestrictor(resourceType, propertyPath, ctx)
+		if valid, msg := propertyRestrictor(value); !valid {
+			resourceValidation.AddWarning(elementPath, preMessage+": "+msg+", but the value is: \""+value+"\"")
 		}
 		break
 	case []interface{}:
-		if isStringList(propertyValue.([]interface{})) {
-			propertyRestrictor = GetRestrictor(propertyName, ctx)
-			for index, value := range propertyValue.([]interface{}) {
-				if valid, msg := propertyRestrictor(value.(string)); !valid {
-					resourceValidation.AddValidationWarning(preMessage + " -> [" + strconv.Itoa(index) + "]: " + msg + ", but the value is: \"" + value.(string) + "\"")
+		if isScalarList(propertyValue.([]interface{})) {
+			propertyRestrictor = GetRestrictor(resourceType, propertyPath, ctx)
+			for index, item := range propertyValue.([]interface{}) {
+				value := scalarToString(item)
+				if valid, msg := propertyRestrictor(value); !valid {
+					resourceValidation.AddWarning(elementPath+"["+strconv.Itoa(index)+"]", preMessage+" -> ["+strconv.Itoa(index)+"]: "+msg+", but the value is: \""+value+"\"")
 				}
 			}
 		} else {
 			for index, value := range propertyValue.([]interface{}) {
-				analyzeObject(propertyName, value, resourceValidation, preMessage+" -> ["+strconv.Itoa(index)+"]", ctx)
+				analyzeObject(resourceType, propertyPath, elementPath+"["+strconv.Itoa(index)+"]", value, resourceValidation, preMessage+" -> ["+strconv.Itoa(index)+"]", ctx)
 			}
 		}
 		break
 	case map[string]interface{}:
-		for k, v := range propertyValue.(map[string]interface{}) {
-			analyzeObject(k, v, resourceValidation, preMessage+" -> "+k, ctx)
+		properties := propertyValue.(map[string]interface{})
+		for _, k := range sortedPropertyNames(properties) {
+			analyzeObject(resourceType, propertyPath+"."+k, elementPath+"."+k, properties[k], resourceValidation, preMessage+" -> "+k, ctx)
 		}
 		break
 	default:
@@ -55,10 +111,31 @@ func analyzeObject(propertyName string, propertyValue interface{}, resourceValid
 	}
 }
 
-func isStringList(list []interface{}) bool {
+func scalarToString(value interface{}) string {
+	switch scalar := value.(type) {
+	case float64:
+		return formatFloat(scalar)
+	case bool:
+		return strconv.FormatBool(scalar)
+	case string:
+		return scalar
+	}
+	return ""
+}
+
+func sortedPropertyNames(properties map[string]interface{}) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isScalarList(list []interface{}) bool {
 	for _, v := range list {
 		switch v.(type) {
-		case string:
+		case string, float64, bool:
 			break
 		default:
 			return false
@@ -0,0 +1,345 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Appliscale/perun/helpers"
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+)
+
+// policyKind tells if the policy is attached to an identity or to a resource. Resource-based policies
+// need a principal, identity-based policies can't have one.
+type policyKind int
+
+const (
+	identityPolicy policyKind = iota
+	resourcePolicy
+)
+
+// policyDocument describes where a policy document is placed in the resource properties.
+type policyDocument struct {
+	Property string
+	// List of inline policies which contain the document in Property, e.g. Policies of AWS::IAM::Role.
+	List string
+	Kind policyKind
+}
+
+// Policy documents of resources which are validated by IsPolicyDocumentValid.
+var policyDocuments = map[string][]policyDocument{
+	"AWS::IAM::Policy":        {{Property: "PolicyDocument", Kind: identityPolicy}},
+	"AWS::IAM::ManagedPolicy": {{Property: "PolicyDocument", Kind: identityPolicy}},
+	"AWS::IAM::RolePolicy":    {{Property: "PolicyDocument", Kind: identityPolicy}},
+	"AWS::IAM::UserPolicy":    {{Property: "PolicyDocument", Kind: identityPolicy}},
+	"AWS::IAM::GroupPolicy":   {{Property: "PolicyDocument", Kind: identityPolicy}},
+	"AWS::IAM::Role": {
+		{Property: "AssumeRolePolicyDocument", Kind: resourcePolicy},
+		{Property: "PolicyDocument", List: "Policies", Kind: identityPolicy},
+	},
+	"AWS::IAM::User":                 {{Property: "PolicyDocument", List: "Policies", Kind: identityPolicy}},
+	"AWS::IAM::Group":                {{Property: "PolicyDocument", List: "Policies", Kind: identityPolicy}},
+	"AWS::S3::BucketPolicy":          {{Property: "PolicyDocument", Kind: resourcePolicy}},
+	"AWS::SQS::QueuePolicy":          {{Property: "PolicyDocument", Kind: resourcePolicy}},
+	"AWS::SNS::TopicPolicy":          {{Property: "PolicyDocument", Kind: resourcePolicy}},
+	"AWS::KMS::Key":                  {{Property: "KeyPolicy", Kind: resourcePolicy}},
+	"AWS::ECR::Repository":           {{Property: "RepositoryPolicyText", Kind: resourcePolicy}},
+	"AWS::Elasticsearch::Domain":     {{Property: "AccessPolicies", Kind: resourcePolicy}},
+	"AWS::OpenSearchService::Domain": {{Property: "AccessPolicies", Kind: resourcePolicy}},
+}
+
+var policyVersions = []string{"2012-10-17", "2008-10-17"}
+var statementElements = []string{"Sid", "Effect", "Principal", "NotPrincipal", "Action", "NotAction", "Resource", "NotResource", "Condition"}
+var principalTypes = []string{"AWS", "Service", "Federated", "CanonicalUser"}
+var conditionOperators = []string{
+	"StringEquals", "StringNotEquals", "StringEqualsIgnoreCase", "StringNotEqualsIgnoreCase", "StringLike", "StringNotLike",
+	"NumericEquals", "NumericNotEquals", "NumericLessThan", "NumericLessThanEquals", "NumericGreaterThan", "NumericGreaterThanEquals",
+	"DateEquals", "DateNotEquals", "DateLessThan", "DateLessThanEquals", "DateGreaterThan", "DateGreaterThanEquals",
+	"Bool", "BinaryEquals", "IpAddress", "NotIpAddress", "ArnEquals", "ArnLike", "ArnNotEquals", "ArnNotLike", "Null",
+}
+
+var actionRegex = regexp.MustCompile(`^[a-zA-Z0-9-]+:[a-zA-Z0-9*?]+$`)
+
+// IsPolicyDocumentValid : Checks if IAM policy documents of the resource are well-formed.
+func IsPolicyDocumentValid(resource template.Resource, resourceValidation *logger.ResourceValidation) bool {
+	valid := true
+	for _, document := range policyDocuments[resource.Type] {
+		if document.List == "" {
+			if value, ok := resource.Properties[document.Property]; ok {
+				valid = checkPolicyDocument(value, "Properties."+document.Property, document.Kind, resourceValidation) && valid
+			}
+			continue
+		}
+		policies, _ := resource.Properties[document.List].([]interface{})
+		for index, policy := range policies {
+			if policy, ok := policy.(map[string]interface{}); ok {
+				if value, ok := policy[document.Property]; ok {
+					path := "Properties." + document.List + "[" + strconv.Itoa(index) + "]." + document.Property
+					valid = checkPolicyDocument(value, path, document.Kind, resourceValidation) && valid
+				}
+			}
+		}
+	}
+	return valid
+}
+
+func checkPolicyDocument(value interface{}, path string, kind policyKind, resourceValidation *logger.ResourceValidation) bool {
+	if text, isString := value.(string); isString {
+		// Some resources (e.g. Elasticsearch domains) take the policy as JSON text.
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(text), &parsed); err != nil {
+			resourceValidation.AddError(path, path+": Policy document is not a valid JSON - "+err.Error())
+			return false
+		}
+		value = parsed
+	}
+	document, isMap := value.(map[string]interface{})
+	if !isMap {
+		resourceValidation.AddError(path, path+": Policy document has to be an object")
+		return false
+	}
+	if isFunction(document) {
+		return true
+	}
+
+	valid := true
+	for _, name := range sortedPropertyNames(document) {
+		if name != "Version" && name != "Id" && name != "Statement" {
+			resourceValidation.AddError(path+"."+name, path+"."+name+": Policy document element "+name+" is not supported"+closestMatch(name, []string{"Version", "Id", "Statement"}))
+			valid = false
+		}
+	}
+	if version, ok := document["Version"]; !ok {
+		resourceValidation.AddWarning(path, path+": Policy document has no Version, so 2008-10-17 is used which doesn't support policy variables")
+	} else if version, isString := version.(string); !isString || !helpers.SliceContains(policyVersions, version) {
+		resourceValidation.AddError(path+".Version", path+".Version: Version has to be one of: "+strings.Join(policyVersions, ", "))
+		valid = false
+	}
+
+	if _, ok := document["Statement"]; !ok {
+		resourceValidation.AddError(path, path+": Policy document has to contain Statement")
+		return false
+	}
+	switch statements := document["Statement"].(type) {
+	case nil:
+		// Value of an intrinsic function which can't be resolved locally.
+	case map[string]interface{}:
+		valid = checkStatement(statements, path+".Statement", kind, resourceValidation) && valid
+	case []interface{}:
+		if len(statements) == 0 {
+			resourceValidation.AddError(path+".Statement", path+".Statement: Statement can't be empty")
+			valid = false
+		}
+		for index, statement := range statements {
+			statementPath := path + ".Statement[" + strconv.Itoa(index) + "]"
+			if statement, isMap := statement.(map[string]interface{}); isMap {
+				valid = checkStatement(statement, statementPath, kind, resourceValidation) && valid
+			} else {
+				resourceValidation.AddError(statementPath, statementPath+": Statement has to be an object")
+				valid = false
+			}
+		}
+	default:
+		resourceValidation.AddError(path+".Statement", path+".Statement: Statement has to be an object or a list of objects")
+		valid = false
+	}
+	return valid
+}
+
+func checkStatement(statement map[string]interface{}, path string, kind policyKind, resourceValidation *logger.ResourceValidation) bool {
+	if isFunction(statement) {
+		return true
+	}
+	errors := []pathError{}
+	addError := func(element string, message string) {
+		errors = append(errors, pathError{joinElementPath(path, element), message})
+	}
+
+	for _, name := range sortedPropertyNames(statement) {
+		if !helpers.SliceContains(statementElements, name) {
+			addError(name, "Statement element "+name+" is not supported"+closestMatch(name, statementElements))
+		}
+	}
+
+	if effect, ok := statement["Effect"]; !ok {
+		addError("", "Statement has to contain Effect")
+	} else if effect != "Allow" && effect != "Deny" && effect != nil && !isFunction(effect) {
+		addError("Effect", "Effect has to be Allow or Deny")
+	}
+
+	_, hasAction := statement["Action"]
+	_, hasNotAction := statement["NotAction"]
+	if hasAction == hasNotAction {
+		addError("", "Statement has to contain exactly one of Action and NotAction")
+	}
+	for _, element := range []string{"Action", "NotAction"} {
+		for _, action := range stringValues(statement[element]) {
+			if action != "*" && !actionRegex.MatchString(action) {
+				addError(element, "Action "+action+" has to be \"*\" or have format service:Action")
+			}
+		}
+	}
+
+	_, hasResource := statement["Resource"]
+	_, hasNotResource := statement["NotResource"]
+	if hasResource && hasNotResource {
+		addError("", "Statement can't contain both Resource and NotResource")
+	} else if kind == identityPolicy && !hasResource && !hasNotResource {
+		addError("", "Statement has to contain Resource or NotResource")
+	}
+
+	principal, hasPrincipal := statement["Principal"]
+	notPrincipal, hasNotPrincipal := statement["NotPrincipal"]
+	if kind == identityPolicy && (hasPrincipal || hasNotPrincipal) {
+		addError("", "Principal and NotPrincipal are not allowed in identity-based policies")
+	} else if kind == resourcePolicy {
+		if hasPrincipal == hasNotPrincipal {
+			addError("", "Statement has to contain exactly one of Principal and NotPrincipal")
+		}
+		if hasPrincipal {
+			errors = append(errors, checkPrincipal(principal, joinElementPath(path, "Principal"))...)
+		}
+		if hasNotPrincipal {
+			errors = append(errors, checkPrincipal(notPrincipal, joinElementPath(path, "NotPrincipal"))...)
+		}
+	}
+
+	if condition, ok := statement["Condition"]; ok {
+		errors = append(errors, checkCondition(condition, joinElementPath(path, "Condition"))...)
+	}
+
+	for _, e := range errors {
+		resourceValidation.AddError(e.path, e.String())
+	}
+	return len(errors) == 0
+}
+
+func checkPrincipal(principal interface{}, path string) (errors []pathError) {
+	switch principal := principal.(type) {
+	case string:
+		if principal != "*" {
+			errors = append(errors, pathError{path, "Principal has to be \"*\" or an object with " + strings.Join(principalTypes, ", ") + " keys"})
+		}
+	case nil:
+		// Value of an intrinsic function which can't be resolved locally.
+	case map[string]interface{}:
+		if isFunction(principal) {
+			return
+		}
+		for _, principalType := range sortedPropertyNames(principal) {
+			if !helpers.SliceContains(principalTypes, principalType) {
+				errors = append(errors, pathError{path + "." + principalType, "Principal type " + principalType + " is not supported" + closestMatch(principalType, principalTypes)})
+			} else if !isStringOrList(principal[principalType]) {
+				errors = append(errors, pathError{path + "." + principalType, "Principal has to be a string or a list of strings"})
+			}
+		}
+	default:
+		errors = append(errors, pathError{path, "Principal has to be \"*\" or an object with " + strings.Join(principalTypes, ", ") + " keys"})
+	}
+	return
+}
+
+func checkCondition(condition interface{}, path string) (errors []pathError) {
+	conditionMap, isMap := condition.(map[string]interface{})
+	if !isMap {
+		return []pathError{{path, "Condition has to be an object"}}
+	}
+	if isFunction(conditionMap) {
+		return
+	}
+	for _, operator := range sortedPropertyNames(conditionMap) {
+		if !isConditionOperator(operator) {
+			errors = append(errors, pathError{path + "." + operator, "Condition operator " + operator + " is not supported" + closestMatch(operator, conditionOperators)})
+			continue
+		}
+		if keys, isMap := conditionMap[operator].(map[string]interface{}); !isMap || len(keys) == 0 {
+			errors = append(errors, pathError{path + "." + operator, "Condition operator has to map condition keys to values"})
+		}
+	}
+	return
+}
+
+// isConditionOperator checks the operator together with ForAllValues:/ForAnyValue: set operators and IfExists suffix.
+func isConditionOperator(operator string) bool {
+	for _, prefix := range []string{"ForAllValues:", "ForAnyValue:"} {
+		operator = strings.TrimPrefix(operator, prefix)
+	}
+	if operator != "NullIfExists" {
+		operator = strings.TrimSuffix(operator, "IfExists")
+	}
+	return helpers.SliceContains(conditionOperators, operator)
+}
+
+// stringValues returns strings from a string or a list of strings. Other values (e.g. intrinsic functions) are skipped.
+func stringValues(value interface{}) (values []string) {
+	switch value := value.(type) {
+	case string:
+		values = append(values, value)
+	case []interface{}:
+		for _, item := range value {
+			if text, isString := item.(string); isString {
+				values = append(values, text)
+			}
+		}
+	}
+	return
+}
+
+func isStringOrList(value interface{}) bool {
+	switch value := value.(type) {
+	case nil, string:
+		return true
+	case []interface{}:
+		for _, item := range value {
+			if _, isString := item.(string); !isString && item != nil && !isFunction(item) {
+				return false
+			}
+		}
+		return true
+	}
+	return isFunction(value)
+}
+
+// isFunction checks if the value is a call of an intrinsic function, whose result is unknown before deployment.
+func isFunction(value interface{}) bool {
+	function, isMap := value.(map[string]interface{})
+	if !isMap || len(function) != 1 {
+		return false
+	}
+	for key := range function {
+		return key == "Ref" || strings.HasPrefix(key, "Fn::")
+	}
+	return false
+}
+
+func joinElementPath(path string, element string) string {
+	if element == "" {
+		return path
+	}
+	return path + "." + element
+}
+
+func closestMatch(name string, candidates []string) string {
+	if match, found := helpers.ClosestMatch(name, candidates); found {
+		return ". Did you mean " + match + "?"
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"testing"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func createResourceFromYaml(t *testing.T, resource string) template.Resource {
+	var parsed template.Resource
+	if err := yaml.Unmarshal([]byte(resource), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestValidPolicyDocuments(t *testing.T) {
+	role := createResourceFromYaml(t, `
+Type: AWS::IAM::Role
+Properties:
+  AssumeRolePolicyDocument:
+    Version: "2012-10-17"
+    Statement:
+      - Effect: Allow
+        Principal:
+          Service: [lambda.amazonaws.com]
+        Action: sts:AssumeRole
+  Policies:
+    - PolicyName: logs
+      PolicyDocument:
+        Version: "2012-10-17"
+        Statement:
+          Effect: Allow
+          Action: [logs:CreateLogStream, "logs:Put*"]
+          Resource: "*"
+          Condition:
+            ForAnyValue:StringLikeIfExists:
+              aws:RequestTag/team: ["a*"]
+`)
+	resourceValidation := logger.ResourceValidation{ResourceName: "Role"}
+
+	assert.True(t, IsPolicyDocumentValid(role, &resourceValidation))
+	assert.Empty(t, resourceValidation.Errors)
+	assert.Empty(t, resourceValidation.Warnings)
+}
+
+func TestInvalidStatement(t *testing.T) {
+	policy := createResourceFromYaml(t, `
+Type: AWS::IAM::ManagedPolicy
+Properties:
+  PolicyDocument:
+    Version: "2012-10-17"
+    Statement:
+      - Effect: allow
+        Action: [s3GetObject]
+        NotAction: "s3:*"
+        Principal: "*"
+        Condition:
+          StringEqual:
+            aws:username: admin
+`)
+	resourceValidation := logger.ResourceValidation{ResourceName: "Policy"}
+
+	assert.False(t, IsPolicyDocumentValid(policy, &resourceValidation))
+	assert.Equal(t, []string{
+		"Properties.PolicyDocument.Statement[0].Effect: Effect has to be Allow or Deny",
+		"Properties.PolicyDocument.Statement[0]: Statement has to contain exactly one of Action and NotAction",
+		"Properties.PolicyDocument.Statement[0].Action: Action s3GetObject has to be \"*\" or have format service:Action",
+		"Properties.PolicyDocument.Statement[0]: Statement has to contain Resource or NotResource",
+		"Properties.PolicyDocument.Statement[0]: Principal and NotPrincipal are not allowed in identity-based policies",
+		"Properties.PolicyDocument.Statement[0].Condition.StringEqual: Condition operator StringEqual is not supported. Did you mean StringEquals?",
+	}, resourceValidation.Errors)
+}
+
+func TestInvalidPolicyDocumentStructure(t *testing.T) {
+	bucketPolicy := createResourceFromYaml(t, `
+Type: AWS::S3::BucketPolicy
+Properties:
+  PolicyDocument:
+    Version: "2012-10-18"
+    Statment: []
+`)
+	resourceValidation := logger.ResourceValidation{ResourceName: "BucketPolicy"}
+
+	assert.False(t, IsPolicyDocumentValid(bucketPolicy, &resourceValidation))
+	assert.Equal(t, []string{
+		"Properties.PolicyDocument.Statment: Policy document element Statment is not supported. Did you mean Statement?",
+		"Properties.PolicyDocument.Version: Version has to be one of: 2012-10-17, 2008-10-17",
+		"Properties.PolicyDocument: Policy document has to contain Statement",
+	}, resourceValidation.Errors)
+}
+
+func TestResourcePolicyPrincipal(t *testing.T) {
+	queuePolicy := createResourceFromYaml(t, `
+Type: AWS::SQS::QueuePolicy
+Properties:
+  PolicyDocument:
+    Statement:
+      - Effect: Allow
+        Action: sqs:SendMessage
+        Resource: "*"
+      - Effect: Deny
+        Action: sqs:*
+        Resource: "*"
+        Principal:
+          Services: sns.amazonaws.com
+`)
+	resourceValidation := logger.ResourceValidation{ResourceName: "QueuePolicy"}
+
+	assert.False(t, IsPolicyDocumentValid(queuePolicy, &resourceValidation))
+	assert.Equal(t, []string{
+		"Properties.PolicyDocument.Statement[0]: Statement has to contain exactly one of Principal and NotPrincipal",
+		"Properties.PolicyDocument.Statement[1].Principal.Services: Principal type Services is not supported. Did you mean Service?",
+	}, resourceValidation.Errors)
+	assert.Equal(t, []string{"Properties.PolicyDocument: Policy document has no Version, so 2008-10-17 is used which doesn't support policy variables"}, resourceValidation.Warnings)
+}
+
+func TestPolicyDocumentAsJSONText(t *testing.T) {
+	domain := template.Resource{
+		Type:       "AWS::Elasticsearch::Domain",
+		Properties: map[string]interface{}{"AccessPolicies": "{\"Version\": \"2012-10-17\", \"Statement\": "},
+	}
+	resourceValidation := logger.ResourceValidation{ResourceName: "Domain"}
+
+	assert.False(t, IsPolicyDocumentValid(domain, &resourceValidation))
+	assert.Contains(t, resourceValidation.Errors[0], "Properties.AccessPolicies: Policy document is not a valid JSON")
+}
@@ -0,0 +1,191 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Appliscale/perun/helpers"
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Limits of Lambda function configuration.
+const (
+	minLambdaMemorySize = 128
+	maxLambdaMemorySize = 10240
+	minLambdaTimeout    = 1
+	maxLambdaTimeout    = 900
+	maxZipFileSize      = 4096
+)
+
+// Lambda runtimes which can be used for new functions, according to
+// https://docs.aws.amazon.com/lambda/latest/dg/lambda-runtimes.html as of October 2026.
+var lambdaRuntimes = []string{
+	"nodejs22.x", "nodejs24.x",
+	"python3.12", "python3.13", "python3.14",
+	"java21", "java25",
+	"dotnet8", "dotnet10",
+	"ruby3.3", "ruby3.4",
+	"provided.al2023",
+}
+
+// Lambda runtimes which reached the end of support. Functions using them can't be created or updated.
+var deprecatedLambdaRuntimes = []string{
+	"nodejs", "nodejs4.3", "nodejs4.3-edge", "nodejs6.10", "nodejs8.10", "nodejs10.x", "nodejs12.x", "nodejs14.x", "nodejs16.x", "nodejs18.x", "nodejs20.x",
+	"python2.7", "python3.6", "python3.7", "python3.8", "python3.9", "python3.10", "python3.11",
+	"java8", "java8.al2", "java11", "java17",
+	"dotnetcore1.0", "dotnetcore2.0", "dotnetcore2.1", "dotnetcore3.1", "dotnet5.0", "dotnet6", "dotnet7",
+	"ruby2.5", "ruby2.7", "ruby3.2",
+	"go1.x", "provided", "provided.al2",
+}
+
+// Handler formats per runtime family. Custom runtimes accept any handler.
+var lambdaHandlerFormats = []struct {
+	RuntimePrefix string
+	Format        *regexp.Regexp
+	Description   string
+}{
+	{"nodejs", regexp.MustCompile(`^[^\s]+\.[^\s./]+$`), "file.function"},
+	{"python", regexp.MustCompile(`^[^\s]+\.[^\s./]+$`), "module.function"},
+	{"ruby", regexp.MustCompile(`^[^\s]+\.[^\s./]+$`), "file.method"},
+	{"java", regexp.MustCompile(`^[\w$.]+(::[\w$]+)?$`), "package.Class::method"},
+	{"dotnet", regexp.MustCompile(`^[\w.]+::[\w.]+::[\w]+$`), "Assembly::Namespace.Class::Method"},
+}
+
+// LambdaFunctionProperties describes structure of Lambda Function.
+type LambdaFunctionProperties struct {
+	Runtime     interface{}
+	Handler     interface{}
+	MemorySize  interface{}
+	Timeout     interface{}
+	PackageType interface{}
+	Code        map[string]interface{}
+}
+
+// IsLambdaFunctionValid : Checks runtime, handler, memory size, timeout and code of Lambda function.
+func IsLambdaFunctionValid(function template.Resource, resourceValidation *logger.ResourceValidation) bool {
+	var properties LambdaFunctionProperties
+	mapstructure.Decode(function.Properties, &properties)
+
+	errors := []pathError{}
+	runtime, isRuntimeKnown := properties.Runtime.(string)
+	if isRuntimeKnown {
+		if helpers.SliceContains(deprecatedLambdaRuntimes, runtime) {
+			resourceValidation.AddWarning("Properties.Runtime", "Properties.Runtime: Runtime "+runtime+" is deprecated, functions using it can't be created or updated")
+		} else if !helpers.SliceContains(lambdaRuntimes, runtime) {
+			errors = append(errors, pathError{"Properties.Runtime", "Runtime " + runtime + " is not supported" + closestMatch(runtime, lambdaRuntimes)})
+		}
+	}
+	if handler, isString := properties.Handler.(string); isString && isRuntimeKnown {
+		errors = append(errors, checkLambdaHandler(handler, runtime)...)
+	}
+
+	if memorySize, isNumber := toNumber(properties.MemorySize); isNumber {
+		if memorySize < minLambdaMemorySize || memorySize > maxLambdaMemorySize {
+			errors = append(errors, pathError{"Properties.MemorySize", "MemorySize has to be between " + strconv.Itoa(minLambdaMemorySize) + " and " + strconv.Itoa(maxLambdaMemorySize) + " MB"})
+		} else if memorySize != math.Trunc(memorySize) {
+			errors = append(errors, pathError{"Properties.MemorySize", "MemorySize has to be set in 1 MB increments"})
+		}
+	}
+	if timeout, isNumber := toNumber(properties.Timeout); isNumber && (timeout < minLambdaTimeout || timeout > maxLambdaTimeout) {
+		errors = append(errors, pathError{"Properties.Timeout", "Timeout has to be between " + strconv.Itoa(minLambdaTimeout) + " and " + strconv.Itoa(maxLambdaTimeout) + " seconds"})
+	}
+
+	errors = append(errors, checkLambdaCode(function.Properties, properties, runtime)...)
+
+	for _, e := range errors {
+		resourceValidation.AddError(e.path, e.String())
+	}
+	return len(errors) == 0
+}
+
+func checkLambdaHandler(handler string, runtime string) []pathError {
+	for _, handlerFormat := range lambdaHandlerFormats {
+		if strings.HasPrefix(runtime, handlerFormat.RuntimePrefix) && !handlerFormat.Format.MatchString(handler) {
+			return []pathError{{"Properties.Handler", "Handler " + handler + " has to have format " + handlerFormat.Description + " for runtime " + runtime}}
+		}
+	}
+	return nil
+}
+
+// checkLambdaCode checks if exactly one code source is set and if it matches the package type.
+func checkLambdaCode(rawProperties map[string]interface{}, properties LambdaFunctionProperties, runtime string) (errors []pathError) {
+	if properties.Code == nil || isFunction(properties.Code) {
+		return
+	}
+	sources := 0
+	for _, source := range []string{"ZipFile", "S3Bucket", "ImageUri"} {
+		if _, isSet := properties.Code[source]; isSet {
+			sources++
+		}
+	}
+	_, hasS3Key := properties.Code["S3Key"]
+	if sources != 1 {
+		errors = append(errors, pathError{"Properties.Code", "Code has to contain exactly one of ZipFile, S3Bucket and S3Key, or ImageUri"})
+	}
+	if _, hasS3Bucket := properties.Code["S3Bucket"]; hasS3Bucket != hasS3Key {
+		errors = append(errors, pathError{"Properties.Code", "S3Bucket and S3Key have to be set together"})
+	}
+	if isFunction(properties.PackageType) {
+		// Package type is set with an intrinsic function, so the code can't be checked against it.
+		return
+	}
+
+	if properties.PackageType == "Image" {
+		if _, hasImage := properties.Code["ImageUri"]; !hasImage {
+			errors = append(errors, pathError{"Properties.Code", "ImageUri is required when PackageType is Image"})
+		}
+		return
+	}
+	if _, hasImage := properties.Code["ImageUri"]; hasImage && properties.PackageType == nil {
+		errors = append(errors, pathError{"Properties.PackageType", "PackageType has to be Image when ImageUri is set"})
+	}
+	for _, property := range []string{"Runtime", "Handler"} {
+		if _, isSet := rawProperties[property]; !isSet {
+			errors = append(errors, pathError{"Properties", property + " is required when PackageType is Zip"})
+		}
+	}
+
+	if zipFile, isString := properties.Code["ZipFile"].(string); isString {
+		if len(zipFile) > maxZipFileSize {
+			errors = append(errors, pathError{"Properties.Code.ZipFile", "Inline code can have at most " + strconv.Itoa(maxZipFileSize) + " characters, but it has " + strconv.Itoa(len(zipFile))})
+		}
+		if runtime != "" && !strings.HasPrefix(runtime, "nodejs") && !strings.HasPrefix(runtime, "python") {
+			errors = append(errors, pathError{"Properties.Code.ZipFile", "Inline code is supported only for Node.js and Python runtimes"})
+		}
+	}
+	return
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case int:
+		return float64(number), true
+	case float64:
+		return number, true
+	case string:
+		if parsed, err := strconv.ParseFloat(number, 64); err == nil {
+			return parsed, true
+		}
+	}
+	return 0, false
+}
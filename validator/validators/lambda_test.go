@@ -0,0 +1,133 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidLambdaFunctions(t *testing.T) {
+	zipFunction := createResourceFromYaml(t, `
+Type: AWS::Lambda::Function
+Properties:
+  Runtime: python3.12
+  Handler: src/app.handler
+  MemorySize: 256
+  Timeout: 30
+  Role: arn:aws:iam::123456789012:role/lambda
+  Code:
+    S3Bucket: bucket
+    S3Key: function.zip
+`)
+	imageFunction := createResourceFromYaml(t, `
+Type: AWS::Lambda::Function
+Properties:
+  PackageType: Image
+  Role: arn:aws:iam::123456789012:role/lambda
+  Code:
+    ImageUri: 123456789012.dkr.ecr.eu-west-1.amazonaws.com/function:latest
+`)
+	conditionalFunction := createResourceFromYaml(t, `
+Type: AWS::Lambda::Function
+Properties:
+  PackageType:
+    Fn::If: [UseImage, Image, Zip]
+  Role: arn:aws:iam::123456789012:role/lambda
+  Code:
+    ImageUri: 123456789012.dkr.ecr.eu-west-1.amazonaws.com/function:latest
+`)
+	resourceValidation := logger.ResourceValidation{ResourceName: "Function"}
+
+	assert.True(t, IsLambdaFunctionValid(zipFunction, &resourceValidation))
+	assert.True(t, IsLambdaFunctionValid(imageFunction, &resourceValidation))
+	assert.True(t, IsLambdaFunctionValid(conditionalFunction, &resourceValidation))
+	assert.Empty(t, resourceValidation.Errors)
+	assert.Empty(t, resourceValidation.Warnings)
+}
+
+func TestInvalidLambdaConfiguration(t *testing.T) {
+	function := createResourceFromYaml(t, `
+Type: AWS::Lambda::Function
+Properties:
+  Runtime: pyhton3.12
+  Handler: index
+  MemorySize: 64
+  Timeout: 901
+  Code:
+    S3Bucket: bucket
+`)
+	resourceValidation := logger.ResourceValidation{ResourceName: "Function"}
+
+	assert.False(t, IsLambdaFunctionValid(function, &resourceValidation))
+	assert.Equal(t, []string{
+		"Properties.Runtime: Runtime pyhton3.12 is not supported. Did you mean python3.12?",
+		"Properties.MemorySize: MemorySize has to be between 128 and 10240 MB",
+		"Properties.Timeout: Timeout has to be between 1 and 900 seconds",
+		"Properties.Code: S3Bucket and S3Key have to be set together",
+	}, resourceValidation.Errors)
+}
+
+func TestLambdaHandlerFormat(t *testing.T) {
+	resourceValidation := logger.ResourceValidation{ResourceName: "Function"}
+	createFunction := func(runtime string, handler string) template.Resource {
+		return template.Resource{
+			Type: "AWS::Lambda::Function",
+			Properties: map[string]interface{}{
+				"Runtime": runtime,
+				"Handler": handler,
+				"Code":    map[string]interface{}{"ZipFile": "exports.handler = async () => {}"},
+			},
+		}
+	}
+
+	assert.True(t, IsLambdaFunctionValid(createFunction("nodejs22.x", "index.handler"), &resourceValidation))
+	assert.False(t, IsLambdaFunctionValid(createFunction("nodejs22.x", "index"), &resourceValidation))
+	assert.False(t, IsLambdaFunctionValid(createFunction("java21", "example.Handler::handle Request"), &resourceValidation))
+	assert.Equal(t, []string{
+		"Properties.Handler: Handler index has to have format file.function for runtime nodejs22.x",
+		"Properties.Handler: Handler example.Handler::handle Request has to have format package.Class::method for runtime java21",
+		"Properties.Code.ZipFile: Inline code is supported only for Node.js and Python runtimes",
+	}, resourceValidation.Errors)
+}
+
+func TestLambdaCode(t *testing.T) {
+	function := template.Resource{
+		Type: "AWS::Lambda::Function",
+		Properties: map[string]interface{}{
+			"Runtime": "nodejs12.x",
+			"Handler": "index.handler",
+			"Code": map[string]interface{}{
+				"ZipFile":  strings.Repeat("x", 4097),
+				"ImageUri": "image",
+			},
+		},
+	}
+	resourceValidation := logger.ResourceValidation{ResourceName: "Function"}
+
+	assert.False(t, IsLambdaFunctionValid(function, &resourceValidation))
+	assert.Equal(t, []string{
+		"Properties.Code: Code has to contain exactly one of ZipFile, S3Bucket and S3Key, or ImageUri",
+		"Properties.PackageType: PackageType has to be Image when ImageUri is set",
+		"Properties.Code.ZipFile: Inline code can have at most 4096 characters, but it has 4097",
+	}, resourceValidation.Errors)
+	assert.Equal(t, []string{"Properties.Runtime: Runtime nodejs12.x is deprecated, functions using it can't be created or updated"}, resourceValidation.Warnings)
+}
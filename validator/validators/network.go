@@ -0,0 +1,155 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+)
+
+// cidrBlock is a parsed CIDR block together with the resource which defines it.
+type cidrBlock struct {
+	ResourceName string
+	Block        string
+	Network      *net.IPNet
+}
+
+func (block cidrBlock) contains(other cidrBlock) bool {
+	ones, _ := block.Network.Mask.Size()
+	otherOnes, _ := other.Network.Mask.Size()
+	return ones <= otherOnes && block.Network.Contains(other.Network.IP)
+}
+
+func (block cidrBlock) overlaps(other cidrBlock) bool {
+	return block.Network.Contains(other.Network.IP) || other.Network.Contains(block.Network.IP)
+}
+
+// ValidateNetworkTopology checks if subnets fit into CIDR blocks of their VPCs (including blocks added with
+// AWS::EC2::VPCCidrBlock) and if CIDR blocks within one VPC don't overlap. Only subnets which refer to a VPC
+// defined in the template are checked for containment. Resources should be taken from a template with unresolved
+// intrinsic functions.
+func ValidateNetworkTopology(resources map[string]template.Resource, sink logger.LoggerInt) bool {
+	valid := true
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	vpcBlocks := make(map[string][]cidrBlock)
+	for _, name := range names {
+		if resources[name].Type != "AWS::EC2::VPC" {
+			continue
+		}
+		if block, ok := parseCidrBlock(name, resources[name].Properties); ok {
+			vpcBlocks[name] = append(vpcBlocks[name], block)
+		}
+	}
+	for _, name := range names {
+		if resources[name].Type != "AWS::EC2::VPCCidrBlock" {
+			continue
+		}
+		vpcName, isReference := getVpcReference(resources[name].Properties)
+		block, ok := parseCidrBlock(name, resources[name].Properties)
+		if !ok || !isReference {
+			continue
+		}
+		if _, isVpc := vpcBlocks[vpcName]; isVpc {
+			for _, existing := range vpcBlocks[vpcName] {
+				if block.overlaps(existing) {
+					sink.AddResourceForValidation(name).AddError("Properties.CidrBlock", "Properties.CidrBlock: CIDR block "+block.Block+
+						" overlaps with CIDR block "+existing.Block+" of "+existing.ResourceName)
+					valid = false
+				}
+			}
+			vpcBlocks[vpcName] = append(vpcBlocks[vpcName], block)
+		}
+	}
+
+	subnetsByVpc := make(map[string][]cidrBlock)
+	for _, name := range names {
+		if resources[name].Type != "AWS::EC2::Subnet" {
+			continue
+		}
+		block, ok := parseCidrBlock(name, resources[name].Properties)
+		vpcName, isReference := getVpcReference(resources[name].Properties)
+		if !ok || vpcName == "" {
+			continue
+		}
+		if blocks, isVpc := vpcBlocks[vpcName]; isVpc && isReference && !isContainedInAny(block, blocks) {
+			sink.AddResourceForValidation(name).AddError("Properties.CidrBlock", "Properties.CidrBlock: CIDR block "+block.Block+
+				" is not within CIDR blocks of VPC "+vpcName+" ("+joinBlocks(blocks)+")")
+			valid = false
+		}
+		for _, other := range subnetsByVpc[vpcName] {
+			if block.overlaps(other) {
+				sink.AddResourceForValidation(name).AddError("Properties.CidrBlock", "Properties.CidrBlock: CIDR block "+block.Block+
+					" overlaps with CIDR block "+other.Block+" of subnet "+other.ResourceName)
+				valid = false
+			}
+		}
+		subnetsByVpc[vpcName] = append(subnetsByVpc[vpcName], block)
+	}
+	return valid
+}
+
+func parseCidrBlock(resourceName string, properties map[string]interface{}) (cidrBlock, bool) {
+	block, isString := properties["CidrBlock"].(string)
+	if !isString {
+		return cidrBlock{}, false
+	}
+	_, network, err := net.ParseCIDR(block)
+	if err != nil || network.IP.To4() == nil {
+		return cidrBlock{}, false
+	}
+	return cidrBlock{ResourceName: resourceName, Block: block, Network: network}, true
+}
+
+// getVpcReference returns the identifier of the VPC. IsReference is true if the VPC is given with Ref, otherwise
+// the identifier is a literal VPC ID.
+func getVpcReference(properties map[string]interface{}) (vpc string, isReference bool) {
+	switch vpcID := properties["VpcId"].(type) {
+	case string:
+		return vpcID, false
+	case map[string]interface{}:
+		if target, ok := vpcID["Ref"].(string); ok && len(vpcID) == 1 {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+func isContainedInAny(block cidrBlock, blocks []cidrBlock) bool {
+	for _, vpcBlock := range blocks {
+		if vpcBlock.contains(block) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinBlocks(blocks []cidrBlock) string {
+	texts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		texts = append(texts, block.Block)
+	}
+	return strings.Join(texts, ", ")
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"testing"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+	"github.com/stretchr/testify/assert"
+)
+
+func createNetworkResource(resourceType string, cidrBlock string, vpcID interface{}) template.Resource {
+	properties := map[string]interface{}{"CidrBlock": cidrBlock}
+	if vpcID != nil {
+		properties["VpcId"] = vpcID
+	}
+	return template.Resource{Type: resourceType, Properties: properties}
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"Ref": name}
+}
+
+func TestValidNetworkTopology(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	resources := map[string]template.Resource{
+		"VPC":     createNetworkResource("AWS::EC2::VPC", "10.0.0.0/16", nil),
+		"SubnetA": createNetworkResource("AWS::EC2::Subnet", "10.0.0.0/24", ref("VPC")),
+		"SubnetB": createNetworkResource("AWS::EC2::Subnet", "10.0.1.0/24", ref("VPC")),
+	}
+
+	assert.True(t, ValidateNetworkTopology(resources, &sink))
+	assert.False(t, sink.HasValidationErrors())
+}
+
+func TestSubnetOutsideOfVpc(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	resources := map[string]template.Resource{
+		"VPC":    createNetworkResource("AWS::EC2::VPC", "10.0.0.0/16", nil),
+		"Subnet": createNetworkResource("AWS::EC2::Subnet", "10.1.0.0/24", ref("VPC")),
+	}
+
+	assert.False(t, ValidateNetworkTopology(resources, &sink))
+	assert.Equal(t, []string{"Properties.CidrBlock: CIDR block 10.1.0.0/24 is not within CIDR blocks of VPC VPC (10.0.0.0/16)"},
+		sink.AddResourceForValidation("Subnet").Errors)
+}
+
+func TestSubnetLargerThanVpc(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	resources := map[string]template.Resource{
+		"VPC":    createNetworkResource("AWS::EC2::VPC", "10.0.0.0/24", nil),
+		"Subnet": createNetworkResource("AWS::EC2::Subnet", "10.0.0.0/16", ref("VPC")),
+	}
+
+	assert.False(t, ValidateNetworkTopology(resources, &sink))
+}
+
+func TestSubnetInAdditionalVpcCidrBlock(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	resources := map[string]template.Resource{
+		"VPC":          createNetworkResource("AWS::EC2::VPC", "10.0.0.0/16", nil),
+		"VPCCidrBlock": createNetworkResource("AWS::EC2::VPCCidrBlock", "10.1.0.0/16", ref("VPC")),
+		"Subnet":       createNetworkResource("AWS::EC2::Subnet", "10.1.0.0/24", ref("VPC")),
+	}
+
+	assert.True(t, ValidateNetworkTopology(resources, &sink))
+}
+
+func TestOverlappingVpcCidrBlock(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	resources := map[string]template.Resource{
+		"VPC":          createNetworkResource("AWS::EC2::VPC", "10.0.0.0/16", nil),
+		"VPCCidrBlock": createNetworkResource("AWS::EC2::VPCCidrBlock", "10.0.128.0/20", ref("VPC")),
+	}
+
+	assert.False(t, ValidateNetworkTopology(resources, &sink))
+	assert.Equal(t, []string{"Properties.CidrBlock: CIDR block 10.0.128.0/20 overlaps with CIDR block 10.0.0.0/16 of VPC"},
+		sink.AddResourceForValidation("VPCCidrBlock").Errors)
+}
+
+func TestOverlappingSubnets(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	resources := map[string]template.Resource{
+		"VPC":      createNetworkResource("AWS::EC2::VPC", "10.0.0.0/16", nil),
+		"SubnetA":  createNetworkResource("AWS::EC2::Subnet", "10.0.0.0/23", ref("VPC")),
+		"SubnetB":  createNetworkResource("AWS::EC2::Subnet", "10.0.1.0/24", ref("VPC")),
+		"OtherVPC": createNetworkResource("AWS::EC2::VPC", "10.0.0.0/16", nil),
+		"SubnetC":  createNetworkResource("AWS::EC2::Subnet", "10.0.1.0/24", ref("OtherVPC")),
+	}
+
+	assert.False(t, ValidateNetworkTopology(resources, &sink))
+	assert.Equal(t, []string{"Properties.CidrBlock: CIDR block 10.0.1.0/24 overlaps with CIDR block 10.0.0.0/23 of subnet SubnetA"},
+		sink.AddResourceForValidation("SubnetB").Errors)
+	assert.Empty(t, sink.AddResourceForValidation("SubnetC").Errors)
+}
+
+func TestOverlappingSubnetsInExternalVpc(t *testing.T) {
+	sink := logger.CreateQuietLogger()
+	resources := map[string]template.Resource{
+		"SubnetA": createNetworkResource("AWS::EC2::Subnet", "10.0.0.0/24", ref("VpcParameter")),
+		"SubnetB": createNetworkResource("AWS::EC2::Subnet", "10.0.0.128/25", ref("VpcParameter")),
+	}
+
+	assert.False(t, ValidateNetworkTopology(resources, &sink))
+}
+
+func TestSubnetPrefixLength(t *testing.T) {
+	resourceValidation := logger.ResourceValidation{ResourceName: "Subnet"}
+	assert.True(t, IsSubnetValid(createNetworkResource("AWS::EC2::Subnet", "10.0.0.0/28", ref("VPC")), &resourceValidation))
+	assert.False(t, IsSubnetValid(createNetworkResource("AWS::EC2::Subnet", "10.0.0.0/30", ref("VPC")), &resourceValidation))
+	assert.False(t, IsVpcCidrBlockValid(createNetworkResource("AWS::EC2::VPCCidrBlock", "10.0.0.0/12", ref("VPC")), &resourceValidation))
+	assert.False(t, IsVpcCidrBlockValid(createNetworkResource("AWS::EC2::VPCCidrBlock", "10.0.0/16", ref("VPC")), &resourceValidation))
+	assert.Equal(t, "Properties.CidrBlock: Invalid CIDR format - 10.0.0/16", resourceValidation.Errors[len(resourceValidation.Errors)-1])
+}
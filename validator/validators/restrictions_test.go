@@ -0,0 +1,100 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/Appliscale/perun/configuration"
+	"github.com/Appliscale/perun/context"
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+	"github.com/stretchr/testify/assert"
+)
+
+func createRestrictionsContext(t *testing.T) *context.Context {
+	quietLogger := logger.CreateQuietLogger()
+	rawDefaults, err := ioutil.ReadFile("../../defaults/restrictions.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := configuration.ParseRestrictionsConfiguration(rawDefaults, &quietLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &context.Context{
+		Logger:             &quietLogger,
+		RestrictionsConfig: config,
+	}
+}
+
+func TestUnrestrictedPropertyPasses(t *testing.T) {
+	valid, _ := GetRestrictor("AWS::S3::Bucket", "AccessControl", createRestrictionsContext(t))("Anything")
+	assert.True(t, valid)
+}
+
+func TestRestrictorChecksLengthAndPattern(t *testing.T) {
+	restrictor := GetRestrictor("AWS::S3::Bucket", "BucketName", createRestrictionsContext(t))
+
+	valid, _ := restrictor("my-bucket.logs")
+	assert.True(t, valid)
+
+	valid, msg := restrictor("ab")
+	assert.False(t, valid)
+	assert.Equal(t, "The value has to be at least 3 characters long", msg)
+
+	valid, msg = restrictor("My_Bucket")
+	assert.False(t, valid)
+	assert.Equal(t, "The value has to match the pattern ^[a-z0-9][a-z0-9.-]*[a-z0-9]$", msg)
+}
+
+func TestRestrictorChecksAllowedValuesAndRange(t *testing.T) {
+	ctx := createRestrictionsContext(t)
+
+	valid, msg := GetRestrictor("AWS::Logs::LogGroup", "RetentionInDays", ctx)("10")
+	assert.False(t, valid)
+	assert.Contains(t, msg, "The value has to be one of: 1, 3, 5")
+
+	valid, msg = GetRestrictor("AWS::SQS::Queue", "DelaySeconds", ctx)("901")
+	assert.False(t, valid)
+	assert.Equal(t, "The value has to be at most 900", msg)
+
+	valid, _ = GetRestrictor("AWS::SQS::Queue", "DelaySeconds", ctx)("30")
+	assert.True(t, valid)
+}
+
+func TestGeneralValidateResourceByName(t *testing.T) {
+	resource := template.Resource{
+		Type: "AWS::SQS::Queue",
+		Properties: map[string]interface{}{
+			"QueueName":    "queue",
+			"DelaySeconds": float64(1000),
+			"Tags": []interface{}{
+				map[string]interface{}{"Key": "", "Value": "value"},
+			},
+		},
+	}
+	resourceValidation := logger.ResourceValidation{ResourceName: "Queue"}
+
+	GeneralValidateResourceByName(resource, &resourceValidation, createRestrictionsContext(t))
+
+	assert.Equal(t, []string{
+		"DelaySeconds : The value has to be at most 900, but the value is: \"1000\"",
+		"Tags  -> [0] -> Key: The value has to be at least 1 characters long, but the value is: \"\"",
+	}, resourceValidation.Warnings)
+}
@@ -0,0 +1,200 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+)
+
+// ruleDirection describes properties which can be used as a source or destination of traffic in security group rules.
+type ruleDirection struct {
+	Name string
+	// Property of AWS::EC2::SecurityGroup which contains rules of this direction.
+	Property string
+	Targets  []string
+}
+
+var ingressDirection = ruleDirection{
+	Name:     "ingress",
+	Property: "SecurityGroupIngress",
+	Targets:  []string{"CidrIp", "CidrIpv6", "SourceSecurityGroupId", "SourceSecurityGroupName", "SourcePrefixListId"},
+}
+
+var egressDirection = ruleDirection{
+	Name:     "egress",
+	Property: "SecurityGroupEgress",
+	Targets:  []string{"CidrIp", "CidrIpv6", "DestinationSecurityGroupId", "DestinationPrefixListId"},
+}
+
+// Protocol names accepted by AWS together with their numbers.
+var protocolNumbers = map[string]int{
+	"tcp":    6,
+	"udp":    17,
+	"icmp":   1,
+	"icmpv6": 58,
+}
+
+// IsSecurityGroupValid : Checks ingress and egress rules defined inside security group.
+func IsSecurityGroupValid(securityGroup template.Resource, resourceValidation *logger.ResourceValidation) bool {
+	valid := true
+	for _, direction := range []ruleDirection{ingressDirection, egressDirection} {
+		rules, _ := securityGroup.Properties[direction.Property].([]interface{})
+		for index, rule := range rules {
+			if rule, isMap := rule.(map[string]interface{}); isMap && !isFunction(rule) {
+				path := "Properties." + direction.Property + "[" + strconv.Itoa(index) + "]"
+				valid = checkSecurityGroupRule(rule, path, direction, resourceValidation) && valid
+			}
+		}
+	}
+	return valid
+}
+
+// IsSecurityGroupIngressValid : Checks standalone ingress rule.
+func IsSecurityGroupIngressValid(ingress template.Resource, resourceValidation *logger.ResourceValidation) bool {
+	return checkSecurityGroupRule(ingress.Properties, "Properties", ingressDirection, resourceValidation)
+}
+
+// IsSecurityGroupEgressValid : Checks standalone egress rule.
+func IsSecurityGroupEgressValid(egress template.Resource, resourceValidation *logger.ResourceValidation) bool {
+	return checkSecurityGroupRule(egress.Properties, "Properties", egressDirection, resourceValidation)
+}
+
+func checkSecurityGroupRule(rule map[string]interface{}, path string, direction ruleDirection, resourceValidation *logger.ResourceValidation) bool {
+	errors := checkRuleTargets(rule, path, direction)
+
+	protocol, protocolKnown := getProtocolNumber(rule["IpProtocol"])
+	if _, isSet := rule["IpProtocol"]; !isSet {
+		errors = append(errors, pathError{path, "IpProtocol is required"})
+	} else if protocolKnown && (protocol < -1 || protocol > 255) {
+		errors = append(errors, pathError{path + ".IpProtocol", "IpProtocol has to be tcp, udp, icmp, icmpv6, -1 or a protocol number between 0 and 255"})
+	} else if protocolKnown {
+		switch protocol {
+		case protocolNumbers["tcp"], protocolNumbers["udp"]:
+			errors = append(errors, checkPortRange(rule, path)...)
+		case protocolNumbers["icmp"], protocolNumbers["icmpv6"]:
+			errors = append(errors, checkIcmpTypeAndCode(rule, path)...)
+		}
+	}
+
+	for _, e := range errors {
+		resourceValidation.AddError(e.path, e.String())
+	}
+	return len(errors) == 0
+}
+
+// checkRuleTargets checks if exactly one source (or destination) of the traffic is set and if CIDR blocks are valid.
+func checkRuleTargets(rule map[string]interface{}, path string, direction ruleDirection) (errors []pathError) {
+	targets := []string{}
+	for _, target := range direction.Targets {
+		if _, isSet := rule[target]; isSet {
+			targets = append(targets, target)
+		}
+	}
+	// Source security group can be identified by its name and owner, so only group identifiers are exclusive.
+	if len(targets) == 0 && rule["SourceSecurityGroupOwnerId"] == nil {
+		errors = append(errors, pathError{path, "Rule has to specify one of " + strings.Join(direction.Targets, ", ")})
+	} else if len(targets) > 1 {
+		errors = append(errors, pathError{path, "Only one of " + strings.Join(targets, ", ") + " can be specified in " + direction.Name + " rule"})
+	}
+
+	if cidr, isString := rule["CidrIp"].(string); isString {
+		if ip, _, err := net.ParseCIDR(cidr); err != nil || ip.To4() == nil {
+			errors = append(errors, pathError{path + ".CidrIp", "Invalid IPv4 CIDR format - " + cidr})
+		}
+	}
+	if cidr, isString := rule["CidrIpv6"].(string); isString {
+		if ip, _, err := net.ParseCIDR(cidr); err != nil || ip.To4() != nil {
+			errors = append(errors, pathError{path + ".CidrIpv6", "Invalid IPv6 CIDR format - " + cidr})
+		}
+	}
+	return
+}
+
+func checkPortRange(rule map[string]interface{}, path string) (errors []pathError) {
+	fromPort, isFromPortKnown := toInteger(rule["FromPort"])
+	toPort, isToPortKnown := toInteger(rule["ToPort"])
+	_, isFromPortSet := rule["FromPort"]
+	_, isToPortSet := rule["ToPort"]
+	if !isFromPortSet || !isToPortSet {
+		return []pathError{{path, "FromPort and ToPort are required for tcp and udp protocols"}}
+	}
+	if isFromPortKnown && (fromPort < 0 || fromPort > 65535) {
+		errors = append(errors, pathError{path + ".FromPort", "Port has to be between 0 and 65535"})
+	}
+	if isToPortKnown && (toPort < 0 || toPort > 65535) {
+		errors = append(errors, pathError{path + ".ToPort", "Port has to be between 0 and 65535"})
+	}
+	if isFromPortKnown && isToPortKnown && fromPort > toPort {
+		errors = append(errors, pathError{path, "FromPort (" + strconv.Itoa(fromPort) + ") can't be greater than ToPort (" + strconv.Itoa(toPort) + ")"})
+	}
+	return
+}
+
+// checkIcmpTypeAndCode checks ICMP rules, in which FromPort is the ICMP type and ToPort is the ICMP code (-1 means all).
+func checkIcmpTypeAndCode(rule map[string]interface{}, path string) (errors []pathError) {
+	icmpType, isTypeKnown := toInteger(rule["FromPort"])
+	icmpCode, isCodeKnown := toInteger(rule["ToPort"])
+	if isTypeKnown && (icmpType < -1 || icmpType > 255) {
+		errors = append(errors, pathError{path + ".FromPort", "ICMP type has to be -1 or between 0 and 255"})
+	}
+	if isCodeKnown && (icmpCode < -1 || icmpCode > 255) {
+		errors = append(errors, pathError{path + ".ToPort", "ICMP code has to be -1 or between 0 and 255"})
+	}
+	if isTypeKnown && isCodeKnown && icmpType == -1 && icmpCode != -1 {
+		errors = append(errors, pathError{path + ".ToPort", "ICMP code has to be -1 when all ICMP types (-1) are allowed"})
+	}
+	return
+}
+
+// getProtocolNumber returns number of the protocol given as a name or a number. Known is false if the protocol
+// is an intrinsic function or has invalid format (reported as -2, so it fails the range check).
+func getProtocolNumber(protocol interface{}) (number int, known bool) {
+	if name, isString := protocol.(string); isString {
+		if number, isName := protocolNumbers[strings.ToLower(name)]; isName {
+			return number, true
+		}
+	}
+	if number, isNumber := toInteger(protocol); isNumber {
+		return number, true
+	}
+	if _, isString := protocol.(string); isString {
+		return -2, true
+	}
+	return 0, false
+}
+
+func toInteger(value interface{}) (int, bool) {
+	switch number := value.(type) {
+	case int:
+		return number, true
+	case float64:
+		if number == math.Trunc(number) {
+			return int(number), true
+		}
+	case string:
+		if parsed, err := strconv.Atoi(number); err == nil {
+			return parsed, true
+		}
+	}
+	return 0, false
+}
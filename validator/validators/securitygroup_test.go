@@ -0,0 +1,116 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"testing"
+
+	"github.com/Appliscale/perun/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidSecurityGroup(t *testing.T) {
+	securityGroup := createResourceFromYaml(t, `
+Type: AWS::EC2::SecurityGroup
+Properties:
+  GroupDescription: web
+  SecurityGroupIngress:
+    - IpProtocol: tcp
+      FromPort: 80
+      ToPort: 443
+      CidrIp: 0.0.0.0/0
+    - IpProtocol: icmp
+      FromPort: -1
+      ToPort: -1
+      CidrIpv6: ::/0
+    - IpProtocol: "-1"
+      SourceSecurityGroupId: sg-12345678
+  SecurityGroupEgress:
+    - IpProtocol: 17
+      FromPort: "53"
+      ToPort: "53"
+      DestinationPrefixListId: pl-12345678
+`)
+	resourceValidation := logger.ResourceValidation{ResourceName: "SecurityGroup"}
+
+	assert.True(t, IsSecurityGroupValid(securityGroup, &resourceValidation))
+	assert.Empty(t, resourceValidation.Errors)
+}
+
+func TestInvalidSecurityGroupRules(t *testing.T) {
+	securityGroup := createResourceFromYaml(t, `
+Type: AWS::EC2::SecurityGroup
+Properties:
+  GroupDescription: web
+  SecurityGroupIngress:
+    - IpProtocol: tcpp
+      CidrIp: 10.0.0.0/8
+    - IpProtocol: tcp
+      FromPort: 443
+      ToPort: 80
+      CidrIp: 10.0.0.0/33
+      SourceSecurityGroupId: sg-12345678
+    - IpProtocol: udp
+      FromPort: 53
+      CidrIpv6: 10.0.0.0/8
+  SecurityGroupEgress:
+    - IpProtocol: icmp
+      FromPort: -1
+      ToPort: 3
+`)
+	resourceValidation := logger.ResourceValidation{ResourceName: "SecurityGroup"}
+
+	assert.False(t, IsSecurityGroupValid(securityGroup, &resourceValidation))
+	assert.Equal(t, []string{
+		"Properties.SecurityGroupIngress[0].IpProtocol: IpProtocol has to be tcp, udp, icmp, icmpv6, -1 or a protocol number between 0 and 255",
+		"Properties.SecurityGroupIngress[1]: Only one of CidrIp, SourceSecurityGroupId can be specified in ingress rule",
+		"Properties.SecurityGroupIngress[1].CidrIp: Invalid IPv4 CIDR format - 10.0.0.0/33",
+		"Properties.SecurityGroupIngress[1]: FromPort (443) can't be greater than ToPort (80)",
+		"Properties.SecurityGroupIngress[2].CidrIpv6: Invalid IPv6 CIDR format - 10.0.0.0/8",
+		"Properties.SecurityGroupIngress[2]: FromPort and ToPort are required for tcp and udp protocols",
+		"Properties.SecurityGroupEgress[0]: Rule has to specify one of CidrIp, CidrIpv6, DestinationSecurityGroupId, DestinationPrefixListId",
+		"Properties.SecurityGroupEgress[0].ToPort: ICMP code has to be -1 when all ICMP types (-1) are allowed",
+	}, resourceValidation.Errors)
+}
+
+func TestStandaloneSecurityGroupRules(t *testing.T) {
+	ingress := createResourceFromYaml(t, `
+Type: AWS::EC2::SecurityGroupIngress
+Properties:
+  GroupId: sg-12345678
+  IpProtocol: 300
+  SourceSecurityGroupName: default
+  SourceSecurityGroupOwnerId: "123456789012"
+`)
+	egress := createResourceFromYaml(t, `
+Type: AWS::EC2::SecurityGroupEgress
+Properties:
+  GroupId: sg-12345678
+  IpProtocol: tcp
+  FromPort: 0
+  ToPort: 70000
+  DestinationSecurityGroupId: sg-87654321
+`)
+	resourceValidation := logger.ResourceValidation{ResourceName: "Rule"}
+
+	assert.False(t, IsSecurityGroupIngressValid(ingress, &resourceValidation))
+	assert.False(t, IsSecurityGroupEgressValid(egress, &resourceValidation))
+	assert.Equal(t, []string{
+		"Properties.IpProtocol: IpProtocol has to be tcp, udp, icmp, icmpv6, -1 or a protocol number between 0 and 255",
+		"Properties.ToPort: Port has to be between 0 and 65535",
+	}, resourceValidation.Errors)
+}
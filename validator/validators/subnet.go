@@ -0,0 +1,40 @@
+// Copyright 2018 Appliscale
+//
+// Maintainers and contributors are listed in README file inside repository.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"github.com/Appliscale/perun/logger"
+	"github.com/Appliscale/perun/validator/template"
+	"github.com/mitchellh/mapstructure"
+)
+
+// SubnetProperties describes structure of Subnet.
+type SubnetProperties struct {
+	CidrBlock           string
+	VpcId               interface{}
+	AvailabilityZone    string
+	MapPublicIpOnLaunch bool
+	Tags                []Tag
+}
+
+// IsSubnetValid : Checks if CIDR block of subnet is valid.
+func IsSubnetValid(subnet template.Resource, resourceValidation *logger.ResourceValidation) bool {
+	var properties SubnetProperties
+	mapstructure.Decode(subnet.Properties, &properties)
+
+	return isCidrBlockValid(properties.CidrBlock, resourceValidation)
+}
@@ -17,6 +17,9 @@
 package validators
 
 import (
+	"net"
+	"strconv"
+
 	"github.com/Appliscale/perun/logger"
 	"github.com/Appliscale/perun/validator/template"
 	"github.com/asaskevich/govalidator"
@@ -32,16 +35,40 @@ type VpcProperties struct {
 	Tags               []Tag
 }
 
+// Prefix lengths of IPv4 CIDR blocks allowed in VPCs and subnets.
+const (
+	minCidrPrefixLength = 16
+	maxCidrPrefixLength = 28
+)
+
 // IsVpcValid : Checks if CIDR block is valid.
 func IsVpcValid(vpc template.Resource, resourceValidation *logger.ResourceValidation) bool {
-	valid := true
 	var properties VpcProperties
 	mapstructure.Decode(vpc.Properties, &properties)
 
-	if properties.CidrBlock != "" && !govalidator.IsCIDR(properties.CidrBlock) {
-		resourceValidation.AddValidationError("Invalid CIDR format - " + properties.CidrBlock)
-		valid = false
-	}
+	return isCidrBlockValid(properties.CidrBlock, resourceValidation)
+}
 
-	return valid
+// IsVpcCidrBlockValid : Checks if CIDR block associated with VPC is valid.
+func IsVpcCidrBlockValid(vpcCidrBlock template.Resource, resourceValidation *logger.ResourceValidation) bool {
+	cidrBlock, _ := vpcCidrBlock.Properties["CidrBlock"].(string)
+	return isCidrBlockValid(cidrBlock, resourceValidation)
+}
+
+// isCidrBlockValid checks the format and the prefix length of IPv4 CIDR block. Empty block (e.g. set with an intrinsic function) is valid.
+func isCidrBlockValid(cidrBlock string, resourceValidation *logger.ResourceValidation) bool {
+	if cidrBlock == "" {
+		return true
+	}
+	if !govalidator.IsCIDR(cidrBlock) {
+		resourceValidation.AddError("Properties.CidrBlock", "Properties.CidrBlock: Invalid CIDR format - "+cidrBlock)
+		return false
+	}
+	_, network, _ := net.ParseCIDR(cidrBlock)
+	if prefixLength, _ := network.Mask.Size(); prefixLength < minCidrPrefixLength || prefixLength > maxCidrPrefixLength {
+		resourceValidation.AddError("Properties.CidrBlock", "Properties.CidrBlock: CIDR block "+cidrBlock+" has prefix length /"+strconv.Itoa(prefixLength)+
+			", but it has to be between /"+strconv.Itoa(minCidrPrefixLength)+" and /"+strconv.Itoa(maxCidrPrefixLength))
+		return false
+	}
+	return true
 }
@@ -53,6 +53,15 @@ func TestInvalidVpc(t *testing.T) {
 	assert.False(t, IsVpcValid(vpc, &resourceValidation))
 }
 
+func TestVpcPrefixLengthOutOfRange(t *testing.T) {
+	resourceValidation := logger.ResourceValidation{
+		ResourceName: "Example",
+	}
+	assert.False(t, IsVpcValid(createVpc("10.0.0.0/8"), &resourceValidation))
+	assert.Equal(t, []string{"Properties.CidrBlock: CIDR block 10.0.0.0/8 has prefix length /8, but it has to be between /16 and /28"}, resourceValidation.Errors)
+	assert.False(t, IsVpcValid(createVpc("10.0.0.0/29"), &resourceValidation))
+}
+
 func createVpc(cidrBlock string) template.Resource {
 	vpc := template.Resource{}
 	properties := make(map[string]interface{})